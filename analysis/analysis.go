@@ -0,0 +1,86 @@
+// Package analysis explores the complete state space of small puzzle shapes
+//
+// It measures how far every configuration is from the goal, which verifies
+// claims like the hardest 3x3 puzzle needing 31 moves.
+package analysis
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/pravj/puzzl/board"
+	"github.com/pravj/puzzl/solver"
+	"io"
+	"strconv"
+)
+
+// MaxTiles is the largest number of cells an exhaustive search can handle,
+// a 3x4 board already needs a byte for each of its 12! configurations,
+// about half a gigabyte of memory
+const MaxTiles int = 12
+
+// Report holds the distance distribution of a board shape
+type Report struct {
+	Rows int `json:"rows"`
+	Cols int `json:"cols"`
+
+	// number of configurations that can reach the goal
+	States int `json:"states"`
+
+	// largest optimal solution length among all the configurations
+	GodsNumber int `json:"gods_number"`
+
+	// number of configurations at each distance from the goal
+	Histogram []int `json:"histogram"`
+
+	// configurations at the largest distance, in the board text format
+	Antipodes []string `json:"antipodes"`
+}
+
+// Analyze runs an exhaustive breadth first search for a rows*cols board
+func Analyze(rows, cols int) (Report, error) {
+	if rows < 2 || cols < 2 || rows*cols > MaxTiles {
+		return Report{}, fmt.Errorf("analysis: %vx%v board is not between 2x2 and %v cells", rows, cols, MaxTiles)
+	}
+
+	space := solver.Explore(rows, cols)
+	report := Report{Rows: rows, Cols: cols, GodsNumber: space.Depth(), Histogram: space.Counts}
+
+	for _, c := range space.Counts {
+		report.States += c
+	}
+
+	space.Each(func(g board.Grid, distance int) {
+		if distance == report.GodsNumber {
+			report.Antipodes = append(report.Antipodes, g.String())
+		}
+	})
+
+	return report, nil
+}
+
+// WriteCSV writes the report as CSV, a row for each distance
+// followed by a row for each antipodal configuration
+func (r Report) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"kind", "depth", "count", "board"})
+
+	for depth, count := range r.Histogram {
+		writer.Write([]string{"histogram", strconv.Itoa(depth), strconv.Itoa(count), ""})
+	}
+
+	for _, a := range r.Antipodes {
+		writer.Write([]string{"antipode", strconv.Itoa(r.GodsNumber), "", a})
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSON writes the report as an indented JSON document
+func (r Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(r)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"github.com/pravj/puzzl/analysis"
+	"io"
+	"os"
+)
+
+// analyzeShape runs the state space analysis for a shape like 3x3,
+// prints a summary and writes the optional CSV and JSON files,
+// returns the exit status for the command
+func analyzeShape(shape, csvPath, jsonPath string) int {
+	var rows, cols int
+	if _, err := fmt.Sscanf(shape, "%dx%d", &rows, &cols); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid board shape %q, expected ROWSxCOLS like 3x3\n", shape)
+		return 2
+	}
+
+	report, err := analysis.Analyze(rows, cols)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	fmt.Printf("%vx%v board: %v states, God's number %v, %v antipodes\n", rows, cols, report.States, report.GodsNumber, len(report.Antipodes))
+	for depth, count := range report.Histogram {
+		fmt.Printf("%4v %v\n", depth, count)
+	}
+
+	if err := writeReport(csvPath, report.WriteCSV); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if err := writeReport(jsonPath, report.WriteJSON); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
+
+// writeReport creates a file and writes into it, nothing is done for an empty path
+func writeReport(path string, write func(io.Writer) error) error {
+	if path == "" {
+		return nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
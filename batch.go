@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"github.com/pravj/puzzl/batch"
+	"io"
+	"os"
+)
+
+// solveBatch solves the boards of a file (- for stdin) and writes
+// the results to another file (- for stdout), returns the exit status for the command
+func solveBatch(inPath, outPath string, opts batch.Options) int {
+	var r io.Reader = os.Stdin
+	if inPath != "-" {
+		file, err := os.Open(inPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer file.Close()
+
+		r = file
+	}
+
+	var w io.Writer = os.Stdout
+	if outPath != "-" {
+		file, err := os.Create(outPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer file.Close()
+
+		w = file
+	}
+
+	if err := batch.Run(r, w, opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,212 @@
+// Package batch solves many puzzles concurrently
+//
+// Boards are read one per line in the board text format, solved by a pool
+// of goroutines and written back in the order they were read.
+package batch
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/pravj/puzzl/board"
+	"github.com/pravj/puzzl/solver"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Output formats of the results
+const (
+	JSONL string = "jsonl"
+	CSV   string = "csv"
+)
+
+// Options controls how a batch is solved and written
+type Options struct {
+	// number of puzzles solved at the same time, all the CPUs when less than one
+	Workers int
+
+	Heuristic solver.Heuristic
+	TieBreak  solver.TieBreak
+
+	// JSONL or CSV
+	Format string
+}
+
+// Result holds the outcome of solving one puzzle
+type Result struct {
+	// zero-based position of the puzzle in the input
+	Index int    `json:"index"`
+	Board string `json:"board"`
+
+	// optimal number of moves and the moves of the blank tile as U, D, L and R
+	Length int    `json:"length"`
+	Moves  string `json:"moves"`
+
+	Nodes  int     `json:"nodes"`
+	Millis float64 `json:"time_ms"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// job is a puzzle line waiting to be solved
+type job struct {
+	index int
+	text  string
+}
+
+// Run reads the puzzles, solves them and writes a result for each of them
+// Blank lines and lines starting with # are skipped.
+func Run(r io.Reader, w io.Writer, opts Options) error {
+	if opts.Format != JSONL && opts.Format != CSV {
+		return fmt.Errorf("batch: unknown format %q, expected %v or %v", opts.Format, JSONL, CSV)
+	}
+
+	workers := opts.Workers
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	jobs := make(chan job)
+	results := make(chan Result)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				results <- solve(j, opts)
+			}
+		}()
+	}
+
+	// reads the input while the workers are solving
+	var readErr error
+	go func() {
+		defer close(jobs)
+
+		scanner := bufio.NewScanner(r)
+		for index := 0; scanner.Scan(); {
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" || strings.HasPrefix(text, "#") {
+				continue
+			}
+
+			jobs <- job{index: index, text: text}
+			index++
+		}
+		readErr = scanner.Err()
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	writeErr := write(w, results, opts.Format)
+	if readErr != nil {
+		return readErr
+	}
+
+	return writeErr
+}
+
+// solve runs the solver for a single puzzle
+func solve(j job, opts Options) Result {
+	result := Result{Index: j.index, Board: j.text}
+
+	b, err := board.Parse(j.text)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Board = b.String()
+
+	h := opts.Heuristic
+	if h == nil {
+		h = solver.DefaultHeuristic
+	}
+
+	start := time.Now()
+	s := solver.NewWithHeuristic(b, h)
+	s.TieBreak = opts.TieBreak
+	s.Solve()
+
+	result.Millis = float64(time.Since(start)) / float64(time.Millisecond)
+	result.Nodes = s.Expanded
+
+	if !s.Solved {
+		result.Error = "no solution found"
+		return result
+	}
+
+	result.Length = s.Moves
+	result.Moves = MoveString(s)
+
+	return result
+}
+
+// MoveString returns the moves of a solved solver's path as U, D, L and R letters
+func MoveString(s *solver.Solver) string {
+	var moves strings.Builder
+
+	previous := s.Start
+	for e := s.Path.Front(); e != nil; e = e.Next() {
+		next := e.Value.(board.Board)
+
+		d, _ := board.Towards(previous, next)
+		moves.WriteString(d.String())
+
+		previous = next
+	}
+
+	return moves.String()
+}
+
+// write writes the results in input order as they come
+func write(w io.Writer, results <-chan Result, format string) error {
+	var err error
+	var csvWriter *csv.Writer
+	var encoder *json.Encoder
+
+	if format == CSV {
+		csvWriter = csv.NewWriter(w)
+		csvWriter.Write([]string{"index", "board", "length", "moves", "nodes", "time_ms", "error"})
+	} else {
+		encoder = json.NewEncoder(w)
+	}
+
+	// results finishing early wait here for the ones before them
+	pending := make(map[int]Result)
+	next := 0
+
+	for result := range results {
+		pending[result.Index] = result
+
+		for r, ok := pending[next]; ok; r, ok = pending[next] {
+			delete(pending, next)
+			next++
+
+			// keeps draining the results after an error, so workers can finish
+			if err != nil {
+				continue
+			}
+
+			if csvWriter != nil {
+				csvWriter.Write([]string{strconv.Itoa(r.Index), r.Board, strconv.Itoa(r.Length), r.Moves,
+					strconv.Itoa(r.Nodes), strconv.FormatFloat(r.Millis, 'f', 3, 64), r.Error})
+				csvWriter.Flush()
+				err = csvWriter.Error()
+			} else {
+				err = encoder.Encode(r)
+			}
+		}
+	}
+
+	return err
+}
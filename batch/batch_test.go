@@ -0,0 +1,115 @@
+package batch
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/pravj/puzzl/board"
+	"github.com/pravj/puzzl/solver"
+	"strings"
+	"testing"
+)
+
+// decode returns the results written in the JSONL format
+func decode(t *testing.T, out *bytes.Buffer) []Result {
+	var results []Result
+
+	decoder := json.NewDecoder(out)
+	for decoder.More() {
+		var r Result
+		if err := decoder.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, r)
+	}
+
+	return results
+}
+
+func TestRunOrder(t *testing.T) {
+	// the hardest boards come first, so the easy ones finish before them
+	boards := []struct {
+		text   string
+		length int
+	}{
+		{"8 6 7 2 5 4 3 0 1", 31},
+		{"6 4 7 8 5 0 3 2 1", 31},
+		{"1 2 3 4 5 6 7 8 0", 0},
+		{"1 2 3 4 5 6 0 7 8", 2},
+		{"1 2 3 4 5 0 7 8 6", 1},
+		{"0 8 7 6 5 4 3 2 1", 28},
+		{"1 2 3 4 0 8 7 6 5", 6},
+	}
+
+	var in strings.Builder
+	for _, b := range boards {
+		in.WriteString(b.text + "\n")
+	}
+
+	var out bytes.Buffer
+	if err := Run(strings.NewReader(in.String()), &out, Options{Workers: 4, Heuristic: solver.Manhattan{}, Format: JSONL}); err != nil {
+		t.Fatal(err)
+	}
+
+	results := decode(t, &out)
+	if len(results) != len(boards) {
+		t.Fatalf("%v results for %v boards", len(results), len(boards))
+	}
+
+	for i, r := range results {
+		if r.Index != i || r.Board != boards[i].text || r.Length != boards[i].length || len(r.Moves) != r.Length || r.Error != "" {
+			t.Errorf("result %v: %+v, want board %v solved in %v moves", i, r, boards[i].text, boards[i].length)
+		}
+
+		// the moves lead to the goal
+		b, _ := board.Parse(r.Board)
+		for _, m := range r.Moves {
+			d, err := board.ParseDirection(m)
+			if err != nil || !b.Slide(d) {
+				t.Errorf("result %v: impossible move %c", i, m)
+				break
+			}
+		}
+		if *b != *board.Goal() {
+			t.Errorf("result %v: the moves %v do not solve %v", i, r.Moves, r.Board)
+		}
+	}
+}
+
+func TestRunInvalidLine(t *testing.T) {
+	in := "# boards\n1 2 3 4 5 6 0 7 8\n\n1 2 3\n1 2 3 4 5 0 7 8 6\n"
+
+	var out bytes.Buffer
+	if err := Run(strings.NewReader(in), &out, Options{Workers: 2, Heuristic: solver.Manhattan{}, Format: CSV}); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a header, then the invalid line in its place between the others
+	want := [][]string{
+		{"0", "1 2 3 4 5 6 0 7 8", "2", ""},
+		{"1", "1 2 3", "0", "error"},
+		{"2", "1 2 3 4 5 0 7 8 6", "1", ""},
+	}
+	if len(records) != len(want)+1 {
+		t.Fatalf("%v records, want a header and %v results", len(records), len(want))
+	}
+
+	for i, w := range want {
+		r := records[i+1]
+		hasError := r[6] != ""
+		if r[0] != w[0] || r[1] != w[1] || r[2] != w[2] || hasError != (w[3] == "error") {
+			t.Errorf("record %v: %q, want index %v, board %v and length %v, error %v", i, r, w[0], w[1], w[2], w[3] == "error")
+		}
+	}
+}
+
+func TestRunUnknownFormat(t *testing.T) {
+	if err := Run(strings.NewReader(""), &bytes.Buffer{}, Options{Format: "xml"}); err == nil {
+		t.Error("Run with format xml: no error")
+	}
+}
@@ -0,0 +1,227 @@
+// Package bench runs solvers and heuristics against well-known puzzle instances
+//
+// It ships Korf's 100 15 puzzle instances and an 8 puzzle instance for every
+// optimal solution length, and reports expanded nodes, time and whether
+// the solutions found are optimal. The same runs are Go benchmarks of the
+// package tests, go test -bench . runs them.
+package bench
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/pravj/puzzl/board"
+	"github.com/pravj/puzzl/solver"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Instance is a puzzle along with its known optimal solution length
+type Instance struct {
+	Name    string
+	Grid    board.Grid
+	Optimal int
+}
+
+// Set is a named list of instances
+type Set struct {
+	Name      string
+	Instances []Instance
+}
+
+// Sets lists the shipped instance sets by name
+var Sets = map[string]func() Set{
+	"korf100": Korf100,
+	"8puzzle": EightPuzzle,
+}
+
+// Korf100 returns Korf's 100 15 puzzle instances, converted to the game's goal
+func Korf100() Set {
+	set := Set{Name: "korf100"}
+
+	for i, k := range korf100 {
+		g, _ := board.ParseGrid(k.tiles)
+		set.Instances = append(set.Instances, Instance{Name: fmt.Sprintf("korf-%v", i+1), Grid: FromKorf(g), Optimal: k.optimal})
+	}
+
+	return set
+}
+
+// EightPuzzle returns the 3x3 instances, one for each optimal solution length
+func EightPuzzle() Set {
+	set := Set{Name: "8puzzle"}
+
+	for _, e := range eightPuzzle {
+		g, _ := board.ParseGrid(e.tiles)
+		set.Instances = append(set.Instances, Instance{Name: fmt.Sprintf("depth-%v", e.optimal), Grid: g, Optimal: e.optimal})
+	}
+
+	return set
+}
+
+// FromKorf converts a configuration from Korf's notation, where the goal
+// has the blank tile first, to the game's goal having it last
+//
+// The board is turned around by 180 degrees and every tile v gets the
+// label n-v, which maps one goal onto the other and keeps all distances.
+func FromKorf(g board.Grid) board.Grid {
+	n := len(g.Tiles)
+	tiles := make([]int, n)
+
+	for i, v := range g.Tiles {
+		if v != 0 {
+			v = n - v
+		}
+		tiles[n-1-i] = v
+	}
+
+	return board.Grid{Rows: g.Rows, Cols: g.Cols, Tiles: tiles}
+}
+
+// ReadSet reads instances from r, one square board per line in the board
+// text format, optionally followed by a colon and the optimal length
+// Blank lines and lines starting with # are skipped, korf tells that the
+// boards are in Korf's notation.
+func ReadSet(r io.Reader, name string, korf bool) (Set, error) {
+	set := Set{Name: name}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		instance := Instance{Name: fmt.Sprintf("%v-%v", name, line), Optimal: -1}
+
+		if i := strings.Index(text, ":"); i >= 0 {
+			optimal, err := strconv.Atoi(strings.TrimSpace(text[i+1:]))
+			if err != nil {
+				return set, fmt.Errorf("bench: line %v: %v", line, err)
+			}
+
+			instance.Optimal = optimal
+			text = text[:i]
+		}
+
+		g, err := board.ParseGrid(text)
+		if err != nil {
+			return set, fmt.Errorf("bench: line %v: %v", line, err)
+		}
+
+		if korf {
+			g = FromKorf(g)
+		}
+		instance.Grid = g
+
+		set.Instances = append(set.Instances, instance)
+	}
+
+	return set, scanner.Err()
+}
+
+// Algorithm is a search algorithm that can be benchmarked
+// Solve returns the solution length and the number of expanded nodes.
+type Algorithm struct {
+	Name  string
+	Solve func(g board.Grid, h solver.Heuristic) (int, int, error)
+}
+
+// Algorithms lists the registered search algorithms by name
+var Algorithms = map[string]Algorithm{
+	"astar":   {Name: "astar", Solve: solveAStar},
+	"idastar": {Name: "idastar", Solve: solveIDAStar},
+}
+
+// Names returns the sorted keys of Sets or Algorithms, for help messages
+func Names(m interface{}) []string {
+	var names []string
+
+	switch m := m.(type) {
+	case map[string]func() Set:
+		for n := range m {
+			names = append(names, n)
+		}
+	case map[string]Algorithm:
+		for n := range m {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// solveAStar runs the game's A-star solver, it only handles 3x3 boards
+func solveAStar(g board.Grid, h solver.Heuristic) (int, int, error) {
+	if g.Rows != board.SIZE || g.Cols != board.SIZE {
+		return 0, 0, fmt.Errorf("astar only solves %vx%v boards", board.SIZE, board.SIZE)
+	}
+
+	b, err := board.Parse(g.String())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	s := solver.NewWithHeuristic(b, h)
+	s.Solve()
+
+	if !s.Solved {
+		return 0, s.Expanded, fmt.Errorf("no solution found")
+	}
+
+	return s.Moves, s.Expanded, nil
+}
+
+// solveIDAStar runs iterative deepening A-star, which handles any shape
+func solveIDAStar(g board.Grid, h solver.Heuristic) (int, int, error) {
+	moves, nodes, ok := solver.IDAStar(g, h)
+	if !ok {
+		return 0, nodes, fmt.Errorf("no solution found")
+	}
+
+	return len(moves), nodes, nil
+}
+
+// Result holds the outcome of running an algorithm on an instance
+type Result struct {
+	Instance Instance
+
+	Length   int
+	Nodes    int
+	Duration time.Duration
+
+	Err error
+}
+
+// Optimal returns whether the solution has the known optimal length,
+// always true when that length is unknown
+func (r Result) Optimal() bool {
+	return r.Err == nil && (r.Instance.Optimal < 0 || r.Length == r.Instance.Optimal)
+}
+
+// Run solves every instance of a set and calls report after each of them
+func Run(set Set, a Algorithm, h solver.Heuristic, report func(Result)) []Result {
+	var results []Result
+
+	for _, instance := range set.Instances {
+		var length, nodes int
+
+		start := time.Now()
+		err := solver.CheckShape(h, instance.Grid.Rows, instance.Grid.Cols)
+		if err == nil {
+			length, nodes, err = a.Solve(instance.Grid, h)
+		}
+
+		result := Result{Instance: instance, Length: length, Nodes: nodes, Duration: time.Since(start), Err: err}
+		results = append(results, result)
+
+		if report != nil {
+			report(result)
+		}
+	}
+
+	return results
+}
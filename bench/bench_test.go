@@ -0,0 +1,88 @@
+package bench
+
+import (
+	"github.com/pravj/puzzl/board"
+	"github.com/pravj/puzzl/solver"
+	"testing"
+)
+
+// benchmarkSet solves a whole set once per iteration and reports the
+// expanded nodes per set as a metric, failing on a missing or non-optimal solution
+func benchmarkSet(b *testing.B, set Set, algorithm string, heuristic string) {
+	h, err := solver.LookupHeuristic(heuristic)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var nodes int
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, r := range Run(set, Algorithms[algorithm], h, nil) {
+			if !r.Optimal() {
+				b.Fatalf("%v: length %v, optimal %v, error %v", r.Instance.Name, r.Length, r.Instance.Optimal, r.Err)
+			}
+
+			nodes += r.Nodes
+		}
+	}
+
+	b.ReportMetric(float64(nodes)/float64(b.N), "nodes/op")
+}
+
+func BenchmarkEightPuzzleAStarManhattan(b *testing.B) {
+	benchmarkSet(b, EightPuzzle(), "astar", "manhattan")
+}
+
+func BenchmarkEightPuzzleIDAStarManhattan(b *testing.B) {
+	benchmarkSet(b, EightPuzzle(), "idastar", "manhattan")
+}
+
+func BenchmarkEightPuzzleIDAStarWalking(b *testing.B) {
+	benchmarkSet(b, EightPuzzle(), "idastar", "walking")
+}
+
+func BenchmarkKorf100IDAStarWalking(b *testing.B) {
+	// a long while per set
+	if testing.Short() {
+		b.Skip("skipped in short mode")
+	}
+
+	benchmarkSet(b, Korf100(), "idastar", "walking,manhattan")
+}
+
+func TestEightPuzzle(t *testing.T) {
+	for _, algorithm := range Names(Algorithms) {
+		for _, r := range Run(EightPuzzle(), Algorithms[algorithm], solver.Manhattan{}, nil) {
+			if !r.Optimal() {
+				t.Errorf("%v %v: length %v, optimal %v, error %v", algorithm, r.Instance.Name, r.Length, r.Instance.Optimal, r.Err)
+			}
+		}
+	}
+}
+
+// TestKorf100 checks the shipped lengths without solving, each one is at least
+// the Manhattan distance and has the same parity as it
+func TestKorf100(t *testing.T) {
+	set := Korf100()
+	if len(set.Instances) != 100 {
+		t.Fatalf("Korf100: %v instances, want 100", len(set.Instances))
+	}
+
+	for _, instance := range set.Instances {
+		estimate := solver.Manhattan{}.Estimate(instance.Grid)
+		if instance.Optimal < estimate || (instance.Optimal-estimate)%2 != 0 {
+			t.Errorf("%v: optimal length %v, Manhattan distance %v", instance.Name, instance.Optimal, estimate)
+		}
+	}
+}
+
+func TestRunUnsupportedShape(t *testing.T) {
+	set := Set{Name: "5x5", Instances: []Instance{{Name: "goal", Grid: board.NewGrid(5, 5), Optimal: 0}}}
+
+	for _, r := range Run(set, Algorithms["idastar"], solver.WalkingDistance{}, nil) {
+		if r.Err == nil {
+			t.Errorf("walking distance on %v: no error for a 5x5 board", r.Instance.Name)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"github.com/pravj/puzzl/bench"
+	"github.com/pravj/puzzl/solver"
+	"os"
+	"strings"
+	"time"
+)
+
+// runBenchmark solves a shipped instance set or the boards of a file with
+// an algorithm and prints nodes, time and optimality for each instance,
+// returns the exit status for the command
+func runBenchmark(setName, algorithm string, h solver.Heuristic, korf bool) int {
+	a, ok := bench.Algorithms[algorithm]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown algorithm %q, available: %v\n", algorithm, strings.Join(bench.Names(bench.Algorithms), ", "))
+		return 2
+	}
+
+	var set bench.Set
+	if load, ok := bench.Sets[setName]; ok {
+		set = load()
+	} else {
+		file, err := os.Open(setName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v is neither a file nor one of: %v\n", setName, strings.Join(bench.Names(bench.Sets), ", "))
+			return 2
+		}
+		defer file.Close()
+
+		set, err = bench.ReadSet(file, setName, korf)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	status := 0
+	var nodes int
+	var elapsed time.Duration
+
+	fmt.Printf("%-12v %6v %8v %12v %12v  %v\n", "instance", "length", "optimal", "nodes", "time", "check")
+	bench.Run(set, a, h, func(r bench.Result) {
+		check := "ok"
+		switch {
+		case r.Err != nil:
+			check, status = r.Err.Error(), 1
+		case !r.Optimal():
+			check, status = "NOT OPTIMAL", 1
+		}
+
+		fmt.Printf("%-12v %6v %8v %12v %12v  %v\n", r.Instance.Name, r.Length, r.Instance.Optimal, r.Nodes, r.Duration.Round(time.Microsecond), check)
+
+		nodes += r.Nodes
+		elapsed += r.Duration
+	})
+	fmt.Printf("%-12v %6v %8v %12v %12v\n", "total", "", "", nodes, elapsed.Round(time.Microsecond))
+
+	return status
+}
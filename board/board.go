@@ -6,9 +6,13 @@ package board
 // Just make sure what all things to add there
 
 import (
+	"fmt"
 	"github.com/pravj/puzzl/scanner"
 	"math/rand"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
 )
 
 const (
@@ -46,6 +50,23 @@ func New() *Board {
 	return board
 }
 
+// Goal returns pointer to a board in the goal configuration,
+// tiles in increasing order with the blank tile last
+func Goal() *Board {
+	board := &Board{size: SIZE}
+	board.initiate()
+
+	for i := 0; i < SIZE; i++ {
+		for j := 0; j < SIZE; j++ {
+			board.Rows[i].Tiles[j].Value = (SIZE*i + j + 1) % (SIZE * SIZE)
+		}
+	}
+
+	board.BlankRow, board.BlankCol = SIZE-1, SIZE-1
+
+	return board
+}
+
 // Initialize all the tile values to zero
 func (b *Board) initiate() {
 	var rows [SIZE]row
@@ -110,7 +131,7 @@ func position(index int) (int, int) {
 // Moves returns a list of all the possible moves from a given tile position
 // TODO: find any idiomatic thing for this, if any.
 func (b *Board) Moves(row, column int) []int {
-	var move []int
+	move := make([]int, 0, 8)
 
 	if column != 0 {
 		move = append(move, row)
@@ -134,3 +155,141 @@ func (b *Board) Moves(row, column int) []int {
 
 	return move
 }
+
+// Direction represents a direction in which the blank tile moves
+type Direction int
+
+// Directions in which the blank tile can be moved
+const (
+	Up Direction = iota
+	Down
+	Left
+	Right
+)
+
+// directionLetters holds the single letter notation for each direction
+var directionLetters = [...]string{"U", "D", "L", "R"}
+
+// String returns the single letter notation of a direction
+func (d Direction) String() string {
+	if d < Up || d > Right {
+		return "-"
+	}
+
+	return directionLetters[d]
+}
+
+// offset returns the row and column change caused by a direction
+func (d Direction) offset() (int, int) {
+	switch d {
+	case Up:
+		return -1, 0
+	case Down:
+		return 1, 0
+	case Left:
+		return 0, -1
+	case Right:
+		return 0, 1
+	}
+
+	return 0, 0
+}
+
+// ParseDirection returns the direction for its single letter notation
+func ParseDirection(r rune) (Direction, error) {
+	switch unicode.ToUpper(r) {
+	case 'U':
+		return Up, nil
+	case 'D':
+		return Down, nil
+	case 'L':
+		return Left, nil
+	case 'R':
+		return Right, nil
+	}
+
+	return Up, fmt.Errorf("board: unknown move %q", r)
+}
+
+// Slide moves the blank tile in the given direction
+// returns false when the move would leave the board
+func (b *Board) Slide(d Direction) bool {
+	dx, dy := d.offset()
+	row, column := b.BlankRow+dx, b.BlankCol+dy
+
+	if row < 0 || row >= SIZE || column < 0 || column >= SIZE || (dx == 0 && dy == 0) {
+		return false
+	}
+
+	b.Move(row, column)
+	return true
+}
+
+// Towards returns the direction that changes one board into the other
+// returns false when they are not a single move apart
+func Towards(from, to Board) (Direction, bool) {
+	for d := Up; d <= Right; d++ {
+		next := from
+		if next.Slide(d) && next == to {
+			return d, true
+		}
+	}
+
+	return Up, false
+}
+
+// String returns the text format of a board,
+// tile values row by row separated by spaces with 0 as the blank tile
+func (b Board) String() string {
+	values := make([]string, 0, SIZE*SIZE)
+
+	for i := 0; i < SIZE; i++ {
+		for j := 0; j < SIZE; j++ {
+			values = append(values, strconv.Itoa(b.Rows[i].Tiles[j].Value))
+		}
+	}
+
+	return strings.Join(values, " ")
+}
+
+// Parse returns pointer to a board described in the text format
+// Values can be separated by spaces, commas or slashes.
+func Parse(text string) (*Board, error) {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r) || r == ',' || r == '/'
+	})
+
+	if len(fields) != SIZE*SIZE {
+		return nil, fmt.Errorf("board: expected %v tiles, found %v", SIZE*SIZE, len(fields))
+	}
+
+	values := make([]int, len(fields))
+	seen := make([]bool, len(fields))
+
+	for i, f := range fields {
+		v, err := strconv.Atoi(f)
+		if err != nil || v < 0 || v >= len(fields) || seen[v] {
+			return nil, fmt.Errorf("board: invalid tile %q", f)
+		}
+
+		values[i] = v
+		seen[v] = true
+	}
+
+	board := &Board{size: SIZE}
+	board.initiate()
+
+	for i := 0; i < SIZE; i++ {
+		for j := 0; j < SIZE; j++ {
+			board.Rows[i].Tiles[j].Value = values[SIZE*i+j]
+		}
+	}
+
+	valid, index := scanner.IsLegal(SIZE, values)
+	if !valid {
+		return nil, fmt.Errorf("board: configuration %q can not be solved", text)
+	}
+	board.BlankRow, board.BlankCol = position(index)
+
+	return board, nil
+}
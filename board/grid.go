@@ -0,0 +1,159 @@
+package board
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Grid is a sliding puzzle configuration of any rows*cols shape
+// Tiles are kept row by row with 0 as the blank tile, the goal being
+// 1, 2, ... n-1 followed by the blank tile, just like the game board.
+type Grid struct {
+	Rows  int
+	Cols  int
+	Tiles []int
+}
+
+// NewGrid returns the goal configuration for a rows*cols shape
+func NewGrid(rows, cols int) Grid {
+	n := rows * cols
+	tiles := make([]int, n)
+
+	for i := range tiles {
+		tiles[i] = (i + 1) % n
+	}
+
+	return Grid{Rows: rows, Cols: cols, Tiles: tiles}
+}
+
+// Grid returns the board configuration as a Grid
+func (b Board) Grid() Grid {
+	var g Grid
+	b.Fill(&g)
+
+	return g
+}
+
+// Fill copies the board configuration into a Grid, reusing its tiles when they fit
+func (b Board) Fill(g *Grid) {
+	if len(g.Tiles) != SIZE*SIZE {
+		g.Tiles = make([]int, SIZE*SIZE)
+	}
+	g.Rows, g.Cols = SIZE, SIZE
+
+	for i := 0; i < SIZE; i++ {
+		for j := 0; j < SIZE; j++ {
+			g.Tiles[i*SIZE+j] = b.Rows[i].Tiles[j].Value
+		}
+	}
+}
+
+// Blank returns zero-based index of the blank tile
+func (g Grid) Blank() int {
+	for i, v := range g.Tiles {
+		if v == 0 {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Target returns zero-based index of a tile value in the goal configuration
+func (g Grid) Target(value int) int {
+	if value == 0 {
+		return len(g.Tiles) - 1
+	}
+
+	return value - 1
+}
+
+// Neighbours returns the configurations a single move away
+func (g Grid) Neighbours() []Grid {
+	var list []Grid
+
+	blank := g.Blank()
+	row, col := blank/g.Cols, blank%g.Cols
+
+	for _, d := range []Direction{Up, Down, Left, Right} {
+		dx, dy := d.offset()
+		if row+dx < 0 || row+dx >= g.Rows || col+dy < 0 || col+dy >= g.Cols {
+			continue
+		}
+
+		tiles := make([]int, len(g.Tiles))
+		copy(tiles, g.Tiles)
+
+		target := (row+dx)*g.Cols + col + dy
+		tiles[blank], tiles[target] = tiles[target], 0
+
+		list = append(list, Grid{Rows: g.Rows, Cols: g.Cols, Tiles: tiles})
+	}
+
+	return list
+}
+
+// String returns the grid in the board text format
+func (g Grid) String() string {
+	values := make([]string, len(g.Tiles))
+
+	for i, v := range g.Tiles {
+		values[i] = strconv.Itoa(v)
+	}
+
+	return strings.Join(values, " ")
+}
+
+// Solvable returns whether the goal can be reached from the configuration
+//
+// Every move keeps the parity of tile inversions combined with the row of
+// the blank tile (only the inversions, for an odd number of columns).
+func (g Grid) Solvable() bool {
+	var inversions int
+	for i := 0; i < len(g.Tiles); i++ {
+		for j := i + 1; j < len(g.Tiles); j++ {
+			if g.Tiles[i] != 0 && g.Tiles[j] != 0 && g.Tiles[i] > g.Tiles[j] {
+				inversions++
+			}
+		}
+	}
+
+	if g.Cols%2 == 0 {
+		inversions += g.Rows - 1 - g.Blank()/g.Cols
+	}
+
+	return inversions%2 == 0
+}
+
+// ParseGrid returns a square grid described in the board text format,
+// the shape is found from the number of tiles
+func ParseGrid(text string) (Grid, error) {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r) || r == ',' || r == '/'
+	})
+
+	size := 2
+	for size*size < len(fields) {
+		size++
+	}
+	if size*size != len(fields) {
+		return Grid{}, fmt.Errorf("board: %v tiles do not make a square board", len(fields))
+	}
+
+	tiles := make([]int, len(fields))
+	seen := make([]bool, len(fields))
+
+	for i, f := range fields {
+		v, err := strconv.Atoi(f)
+		if err != nil || v < 0 || v >= len(fields) || seen[v] {
+			return Grid{}, fmt.Errorf("board: invalid tile %q", f)
+		}
+
+		tiles[i] = v
+		seen[v] = true
+	}
+
+	return Grid{Rows: size, Cols: size, Tiles: tiles}, nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"github.com/pravj/puzzl/solver"
+	"os"
+)
+
+// checkShapes are the board shapes small enough to verify exhaustively
+var checkShapes = [][2]int{{2, 3}, {3, 3}, {2, 4}}
+
+// checkHeuristic verifies a named heuristic for admissibility and consistency,
+// comma separated names are checked as the maximum of them
+// and returns the exit status for the command
+func checkHeuristic(name string) int {
+	h, err := solver.LookupHeuristic(name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	status := 0
+	for _, shape := range checkShapes {
+		if err := solver.CheckShape(h, shape[0], shape[1]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 1
+			continue
+		}
+
+		report := solver.CheckHeuristic(h, shape[0], shape[1])
+
+		fmt.Printf("%v on %vx%v: %v states, admissible %v (%v violations), consistent %v (%v violations)\n",
+			name, report.Rows, report.Cols, report.States,
+			report.Admissible(), report.Inadmissible, report.Consistent(), report.Inconsistent)
+
+		for _, v := range report.Violations {
+			fmt.Printf("  %v\n", v)
+		}
+
+		if !report.Admissible() || !report.Consistent() {
+			status = 1
+		}
+	}
+
+	return status
+}
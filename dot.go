@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"github.com/pravj/puzzl/board"
+	"github.com/pravj/puzzl/solver"
+	"io"
+	"os"
+)
+
+// writeSearchTree solves a board with a tracer attached and writes
+// the explored search tree as a DOT graph, returns the exit status for the command
+func writeSearchTree(path string, b *board.Board, h solver.Heuristic, tie solver.TieBreak, depth int) int {
+	s := solver.NewWithHeuristic(b, h)
+	s.TieBreak = tie
+	s.Tracer = solver.NewTracer(depth)
+	s.Solve()
+
+	var w io.Writer = os.Stdout
+	if path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer file.Close()
+
+		w = file
+	}
+
+	if err := s.Tracer.WriteDOT(w, s); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fmt.Fprintf(os.Stderr, "%v solved in %v moves, %v nodes expanded, %v written\n", b, s.Moves, s.Expanded, s.Tracer.Len())
+	return 0
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/pravj/puzzl/locale"
+	"github.com/pravj/puzzl/notification"
+	"os"
+)
+
+// logEvents subscribes to the bus and writes every event to a file, with its
+// message translated by the catalog, until the bus is closed
+// The returned channel is closed once it is done, nothing is logged for an empty path.
+func logEvents(path string, bus *notification.Bus, catalog *locale.Catalog) (<-chan bool, error) {
+	done := make(chan bool)
+	if path == "" {
+		close(done)
+		return done, nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := bus.Subscribe()
+
+	go func() {
+		defer close(done)
+		defer file.Close()
+
+		writer := bufio.NewWriter(file)
+		for e := range sub.Events {
+			fmt.Fprintf(writer, "%v %v %v: %v\n", e.Time.Format("15:04:05"), e.Severity, e.Kind, catalog.Text(e.Message, e.Args...))
+		}
+
+		writer.Flush()
+	}()
+
+	return done, nil
+}
@@ -0,0 +1,473 @@
+// Package game implements the rules of the game, independent of any interface
+//
+// A Game owns the board, the solver and the scorer. It judges player moves,
+// keeps the hint budget, detects completion and re-solves after wrong moves,
+// leaving the drawing and input handling to a frontend like the surface.
+package game
+
+import (
+	"container/list"
+	"github.com/pravj/puzzl/board"
+	"github.com/pravj/puzzl/locale"
+	"github.com/pravj/puzzl/notification"
+	"github.com/pravj/puzzl/score"
+	"github.com/pravj/puzzl/solver"
+)
+
+// DefaultHints is the number of hints a player gets in a game
+const DefaultHints int = 3
+
+// State is a snapshot of a game for frontends to render
+type State struct {
+	Board board.Board
+
+	Score       float64
+	TotalMoves  int
+	PlayerTotal int
+
+	// moves left on the solver's plan, known once the board is solved
+	SolvableMoves int
+	Solved        bool
+
+	Complete bool
+	Hints    int
+
+	// zero-based current stage and the number of stages, zero for a regular game
+	Stage  int
+	Stages int
+
+	// difficulty of the starting board, Level is zero until it is rated
+	Rating solver.Difficulty
+}
+
+// Plan is a solver that has solved the starting board, along with the board's rating
+type Plan struct {
+	Solver *solver.Solver
+	Rating solver.Difficulty
+}
+
+// stagePlan is the plan of a stage, solved off the goroutine owning the game
+type stagePlan struct {
+	stage   int
+	solver  *solver.Solver
+	optimal *solver.Optimal
+}
+
+// snapshot is what Undo restores
+type snapshot struct {
+	board         board.Board
+	solver        *solver.Solver
+	optimal       *solver.Optimal
+	current       *list.Element
+	solvableMoves int
+	stage         int
+}
+
+// Game represents the rules and the progress of a game
+type Game struct {
+	board board.Board
+
+	// solver of the current board, nil until the plan of the starting board arrives
+	solver *solver.Solver
+
+	// exact distances, any move on an optimal solution is a right move
+	optimal *solver.Optimal
+
+	// plans for nearby boards solved ahead of player moves
+	presolver *solver.Presolver
+
+	// partial goals of a staged game, played one after another
+	stages []solver.PartialGoal
+	stage  int
+
+	// plans of the stages as they are solved, closing done stops sending them
+	stagePlans chan stagePlan
+	done       chan struct{}
+
+	// position of the next board on the solver's plan
+	current *list.Element
+
+	scorer        *score.Score
+	solvableMoves int
+
+	rating solver.Difficulty
+
+	hints    int
+	complete bool
+
+	history []snapshot
+	events  []notification.Event
+
+	// optional bus every event is published on, for listeners besides the renderer
+	Bus *notification.Bus
+}
+
+// New returns pointer to a new Game instance
+// stages is nil for a regular game, otherwise optimal o must be for the first stage
+// The presolver works out plans ahead of the player, it should have the settings of the plan's solver.
+func New(b board.Board, o *solver.Optimal, stages []solver.PartialGoal, p *solver.Presolver) *Game {
+	return &Game{board: b, optimal: o, presolver: p, stages: stages, stagePlans: make(chan stagePlan), done: make(chan struct{}),
+		scorer: score.New(), hints: DefaultHints}
+}
+
+// Action represents a player input
+type Action int
+
+// Player inputs, the moves are in the same order as board directions
+const (
+	MoveUp Action = iota
+	MoveDown
+	MoveLeft
+	MoveRight
+	AskHint
+	TakeBack
+	Refresh
+	Quit
+)
+
+// Start solves the boards around the starting one ahead of the first move
+func (g *Game) Start() {
+	if g.stages == nil {
+		g.presolver.Prefetch(g.board)
+	}
+}
+
+// Do carries out a player action other than Quit
+func (g *Game) Do(a Action) notification.Event {
+	switch a {
+	case AskHint:
+		return g.Hint()
+	case TakeBack:
+		return g.Undo()
+	case Refresh:
+		// nothing happened in the game, it is not recorded
+		return notification.NewEvent(notification.Redraw, "")
+	case Quit:
+		return g.emit(notification.NewEvent(notification.GameOver, notification.QuitMessage))
+	}
+
+	return g.Move(board.Direction(a))
+}
+
+// Run owns the game until the actions are closed or a Quit action arrives
+//
+// Player actions, the plan for the starting board and notices from outside,
+// which may be nil, all come in over channels, so only the goroutine calling
+// Run ever touches the game. The plans of later stages come in the same way.
+// Every event is passed to render along with the updated state, other
+// listeners subscribe to the Bus.
+func (g *Game) Run(actions <-chan Action, plans <-chan Plan, notices <-chan notification.Event, render func(State, notification.Event)) {
+	defer close(g.done)
+
+	g.Start()
+
+	for {
+		select {
+		case a, ok := <-actions:
+			if !ok || a == Quit {
+				return
+			}
+
+			if e := g.Do(a); e.Kind == notification.Redraw {
+				render(g.State(), e)
+			}
+
+		case p, ok := <-plans:
+			if !ok {
+				plans = nil
+				continue
+			}
+			g.Ready(p)
+
+		case p := <-g.stagePlans:
+			if g.stageReady(p) {
+				render(g.State(), notification.NewEvent(notification.Redraw, ""))
+			}
+
+		case n, ok := <-notices:
+			if !ok {
+				notices = nil
+				continue
+			}
+			g.emit(n)
+		}
+
+		for _, e := range g.Events() {
+			render(g.State(), e)
+		}
+	}
+}
+
+// Ready takes the plan for the starting board, moves are judged from then on
+//
+// The plan is handed over along with its solver, which must not be used
+// by anyone else afterwards.
+func (g *Game) Ready(p Plan) notification.Event {
+	g.solver = p.Solver
+	g.rating = p.Rating
+	g.follow()
+
+	// the starting board may already be past the first stages
+	if g.stages != nil {
+		g.advance()
+	}
+
+	return g.emit(notification.NewEvent(notification.Ready, notification.ReadyToPlayMessage))
+}
+
+// stageReady takes the plan of the current stage, unless the player took
+// back the move that started the stage before it arrived
+func (g *Game) stageReady(p stagePlan) bool {
+	if g.solver != nil || p.stage != g.stage || p.solver.Start != g.board {
+		return false
+	}
+
+	g.solver, g.optimal = p.solver, p.optimal
+	g.follow()
+
+	return true
+}
+
+// solved returns whether the plan for the starting board has arrived
+func (g *Game) solved() bool {
+	return g.solver != nil && g.solver.Solved
+}
+
+// follow starts following the solver's plan from its beginning
+func (g *Game) follow() {
+	g.current = g.solver.Path.Front()
+	g.solvableMoves = g.solver.Path.Len()
+}
+
+// State returns a snapshot of the game
+func (g *Game) State() State {
+	return State{
+		Board:         g.board,
+		Score:         g.scorer.Value(),
+		TotalMoves:    int(g.scorer.TotalMoves),
+		PlayerTotal:   int(g.scorer.PlayerTotal),
+		SolvableMoves: g.solvableMoves,
+		Solved:        g.solved(),
+		Complete:      g.complete,
+		Hints:         g.hints,
+		Stage:         g.stage,
+		Stages:        len(g.stages),
+		Rating:        g.rating,
+	}
+}
+
+// Events returns the events since the last call, oldest first
+func (g *Game) Events() []notification.Event {
+	events := g.events
+	g.events = nil
+
+	return events
+}
+
+// emit records an event and returns it
+func (g *Game) emit(e notification.Event) notification.Event {
+	g.events = append(g.events, e)
+
+	if g.Bus != nil {
+		g.Bus.Publish(e)
+	}
+
+	return e
+}
+
+// Move moves the blank tile in a direction and judges the move
+func (g *Game) Move(d board.Direction) notification.Event {
+	if g.complete {
+		return g.emit(notification.NewEvent(notification.GameOver, notification.QuitMessage))
+	}
+
+	next := g.board
+	if !next.Slide(d) {
+		event := notification.NewEvent(notification.ImpossibleMove, notification.ImpossibleMoveMessage)
+		event.Direction = d
+
+		return g.emit(event)
+	}
+
+	if !g.solved() {
+		return g.emit(notification.NewEvent(notification.Wait, notification.WaitMessage))
+	}
+
+	g.history = append(g.history, g.snapshot())
+
+	previous := g.board
+	g.board = next
+
+	// updates the total game moves played till now
+	g.scorer.TotalMoves++
+
+	var event notification.Event
+	delta := 1
+
+	// right move by player, any move that keeps the optimal distance decreasing
+	if g.optimal.IsOptimalMove(previous, g.board) {
+		if g.current.Value.(board.Board) == g.board {
+			if g.current.Next() != nil {
+				g.current = g.current.Next()
+			}
+		} else if path := g.optimal.Path(g.board); path.Len() > 0 {
+			// player took another optimal solution, follow that one from now on
+			g.solver = g.solver.Follow(&g.board, path)
+			g.current = g.solver.Path.Front()
+		}
+
+		g.solvableMoves--
+		g.scorer.PlayerTotal++
+
+		event = notification.NewEvent(notification.RightMove, notification.RightMoveMessage)
+	} else {
+		// wrong move by player, use the presolved plan when it is ready
+		// otherwise replanning reuses the rest of the previous plan
+		// A stage needs a new search, which is done off the goroutine owning the game.
+		if g.stages != nil {
+			g.solveStage(g.optimal)
+		} else {
+			if presolved, ok := g.presolver.Lookup(g.board); ok {
+				g.solver = presolved
+			} else {
+				g.solver = g.solver.Replan(previous, g.current, &g.board, g.optimal)
+			}
+
+			g.current = g.solver.Path.Front()
+			g.solvableMoves = g.solver.Path.Len()
+		}
+
+		g.scorer.PlayerTotal--
+		delta = -1
+
+		event = notification.NewEvent(notification.WrongMove, notification.WrongMoveMessage)
+	}
+
+	// solves the boards around the new one before the next move
+	if g.stages == nil {
+		g.presolver.Prefetch(g.board)
+	}
+
+	// current stage of a staged game is over, move on to the next one
+	if g.stages != nil && g.advance() {
+		event = notification.NewEvent(notification.StageComplete, notification.StageCompleteMessage)
+	}
+
+	// solved by player too. Bingo.
+	if g.board == *board.Goal() {
+		g.complete = true
+		event = notification.NewEvent(notification.GameComplete, notification.GameCompleteMessage)
+	}
+
+	// a completed stage or game reports the move that did it too
+	event.Direction, event.ScoreDelta = d, delta
+
+	return g.emit(event)
+}
+
+// advance moves a staged game past every stage the board already satisfies
+// and returns whether it moved, moves wait until the new stage is solved
+func (g *Game) advance() bool {
+	stage := g.stage
+	for g.stage < len(g.stages)-1 && g.stages[g.stage].Reached(g.board) {
+		g.stage++
+	}
+
+	if g.stage == stage {
+		return false
+	}
+
+	g.solveStage(nil)
+
+	return true
+}
+
+// solveStage solves the current stage from the board off the goroutine owning
+// the game, moves wait until the plan comes back to Run like the plan of the
+// starting board. The distances of the stage are worked out too when o is nil.
+func (g *Game) solveStage(o *solver.Optimal) {
+	g.solver, g.current, g.solvableMoves = nil, nil, 0
+
+	p, b, goal := stagePlan{stage: g.stage, optimal: o}, g.board, g.stages[g.stage]
+	go func() {
+		if p.optimal == nil {
+			p.optimal = solver.NewOptimal(goal.States(b)...)
+		}
+		p.solver = solver.NewPartial(&b, goal)
+		p.solver.Solve()
+
+		select {
+		case g.stagePlans <- p:
+		case <-g.done:
+		}
+	}()
+}
+
+// Hint tells the next move of the solver's plan, there is a limit for hints though
+func (g *Game) Hint() notification.Event {
+	if g.complete {
+		return g.emit(notification.NewEvent(notification.GameOver, notification.QuitMessage))
+	}
+
+	if !g.solved() {
+		return g.emit(notification.NewEvent(notification.Wait, notification.WaitMessage))
+	}
+
+	if g.hints <= 0 {
+		return g.emit(notification.NewEvent(notification.NoHints, notification.NoHintsMessage))
+	}
+
+	// no next board, or one the blank can't get to, leaves nothing to hint
+	var d board.Direction
+	ok := g.current != nil
+	if ok {
+		d, ok = board.Towards(g.board, g.current.Value.(board.Board))
+	}
+	if !ok {
+		return g.emit(notification.NewEvent(notification.NoHints, notification.NothingToHintMessage))
+	}
+
+	g.hints--
+
+	event := notification.NewEvent(notification.Hint, notification.HintMessage)
+	event.Direction = d
+	event.Args = []interface{}{g.hints, DefaultHints - g.hints, locale.Key(directionNames[d])}
+
+	return g.emit(event)
+}
+
+// directionNames holds the message key used in hints for each direction
+var directionNames = [...]string{"up", "down", "left", "right"}
+
+// Undo takes back the last move, the score keeps counting it though
+func (g *Game) Undo() notification.Event {
+	if g.complete {
+		return g.emit(notification.NewEvent(notification.GameOver, notification.QuitMessage))
+	}
+
+	if len(g.history) == 0 {
+		return g.emit(notification.NewEvent(notification.NothingToUndo, notification.NothingToUndoMessage))
+	}
+
+	last := g.history[len(g.history)-1]
+	g.history = g.history[:len(g.history)-1]
+
+	d, _ := board.Towards(g.board, last.board)
+
+	g.board, g.solver, g.optimal = last.board, last.solver, last.optimal
+	g.current, g.solvableMoves, g.stage = last.current, last.solvableMoves, last.stage
+
+	if g.stages == nil {
+		g.presolver.Prefetch(g.board)
+	}
+
+	event := notification.NewEvent(notification.Undo, notification.UndoMessage)
+	event.Direction = d
+
+	return g.emit(event)
+}
+
+// snapshot returns what Undo needs to restore the current position
+func (g *Game) snapshot() snapshot {
+	return snapshot{board: g.board, solver: g.solver, optimal: g.optimal, current: g.current, solvableMoves: g.solvableMoves, stage: g.stage}
+}
@@ -0,0 +1,466 @@
+package game
+
+import (
+	"github.com/pravj/puzzl/board"
+	"github.com/pravj/puzzl/notification"
+	"github.com/pravj/puzzl/solver"
+	"testing"
+	"time"
+)
+
+// mustParse returns the board of a text, failing the test when it is invalid
+func mustParse(t *testing.T, text string) *board.Board {
+	b, err := board.Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+// rendered is what Run passes to the render function
+type rendered struct {
+	state State
+	event notification.Event
+}
+
+// running is a game owned by Run on another goroutine
+type running struct {
+	actions chan Action
+	plans   chan Plan
+	notices chan notification.Event
+
+	renders chan rendered
+	stopped chan bool
+}
+
+// run starts Run for a game on its own goroutine
+func run(g *Game) *running {
+	r := &running{
+		actions: make(chan Action),
+		plans:   make(chan Plan),
+		notices: make(chan notification.Event),
+		renders: make(chan rendered, 64),
+		stopped: make(chan bool),
+	}
+
+	go func() {
+		g.Run(r.actions, r.plans, r.notices, func(s State, e notification.Event) {
+			r.renders <- rendered{s, e}
+		})
+		close(r.stopped)
+	}()
+
+	return r
+}
+
+// waitFor returns the first render that satisfies a condition, failing the test after a while
+func (r *running) waitFor(t *testing.T, what string, ok func(rendered) bool) rendered {
+	timeout := time.After(10 * time.Second)
+
+	for {
+		select {
+		case rd := <-r.renders:
+			if ok(rd) {
+				return rd
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %v", what)
+		}
+	}
+}
+
+// waitForEvent returns the first render of an event kind
+func (r *running) waitForEvent(t *testing.T, kind notification.Kind) rendered {
+	return r.waitFor(t, kind.String(), func(rd rendered) bool {
+		return rd.event.Kind == kind
+	})
+}
+
+// stop closes the actions and waits for Run to return
+func (r *running) stop(t *testing.T) {
+	close(r.actions)
+
+	select {
+	case <-r.stopped:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run did not return after the actions were closed")
+	}
+}
+
+func TestRunStages(t *testing.T) {
+	tests := []struct {
+		start string
+		moves []Action
+	}{
+		// the top row is in place from the start
+		{"1 2 3 4 5 6 0 7 8", []Action{MoveRight, MoveRight}},
+
+		// the first move completes the top row
+		{"1 2 0 4 5 3 7 8 6", []Action{MoveLeft, MoveRight, MoveDown, MoveDown}},
+
+		// a wrong move waits for a new plan of the stage
+		{"1 2 3 4 5 6 0 7 8", []Action{MoveUp, MoveDown, MoveRight, MoveRight}},
+	}
+
+	stages, err := solver.TeachingStagesFor(board.SIZE, board.SIZE)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range tests {
+		b := mustParse(t, test.start)
+		s := solver.NewPartial(b, stages[0])
+		s.Solve()
+
+		g := New(*b, solver.NewOptimal(stages[0].States(*b)...), stages, solver.NewPresolver())
+		r := run(g)
+		r.plans <- Plan{Solver: s}
+
+		for i, a := range test.moves {
+			// moves of a stage wait for its plan
+			r.waitFor(t, "the plan of the stage", func(rd rendered) bool {
+				return rd.state.Solved && (i > 0 || rd.state.Stage > 0 || rd.event.Kind == notification.Ready)
+			})
+			r.actions <- a
+		}
+
+		last := r.waitForEvent(t, notification.GameComplete)
+		if last.state.Stage != len(stages)-1 || !last.state.Complete {
+			t.Errorf("%v: completed at stage %v of %v, complete %v", test.start, last.state.Stage, last.state.Stages, last.state.Complete)
+		}
+
+		r.stop(t)
+	}
+}
+
+// newGame returns a regular game for a board whose plan is already solved
+func newGame(t *testing.T, text string) *Game {
+	b := mustParse(t, text)
+
+	presolver := solver.NewPresolver()
+	presolver.Heuristic = solver.Manhattan{}
+
+	s := solver.NewWithHeuristic(b, solver.Manhattan{})
+	s.Solve()
+
+	g := New(*b, solver.NewOptimal(*board.Goal()), nil, presolver)
+	g.Ready(Plan{Solver: s})
+	g.Events()
+
+	return g
+}
+
+// play carries out actions and returns the kinds of the events they caused
+func play(g *Game, actions []Action) []notification.Kind {
+	var kinds []notification.Kind
+	for _, a := range actions {
+		kinds = append(kinds, g.Do(a).Kind)
+	}
+
+	return kinds
+}
+
+// sameKinds returns whether two lists of event kinds are equal
+func sameKinds(a, b []notification.Kind) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestMove(t *testing.T) {
+	tests := []struct {
+		name    string
+		actions []Action
+		kinds   []notification.Kind
+
+		// state after the actions
+		board         string
+		playerTotal   int
+		totalMoves    int
+		solvableMoves int
+		complete      bool
+	}{
+		{"right", []Action{MoveRight},
+			[]notification.Kind{notification.RightMove},
+			"1 2 3 4 5 6 7 0 8", 1, 1, 1, false},
+		{"wrong", []Action{MoveUp},
+			[]notification.Kind{notification.WrongMove},
+			"1 2 3 0 5 6 4 7 8", -1, 1, 3, false},
+		{"wrong and back", []Action{MoveUp, MoveDown},
+			[]notification.Kind{notification.WrongMove, notification.RightMove},
+			"1 2 3 4 5 6 0 7 8", 0, 2, 2, false},
+		{"off the board", []Action{MoveLeft, MoveDown},
+			[]notification.Kind{notification.ImpossibleMove, notification.ImpossibleMove},
+			"1 2 3 4 5 6 0 7 8", 0, 0, 2, false},
+		{"complete", []Action{MoveRight, MoveRight},
+			[]notification.Kind{notification.RightMove, notification.GameComplete},
+			"1 2 3 4 5 6 7 8 0", 2, 2, 0, true},
+		{"complete after a wrong move", []Action{MoveRight, MoveUp, MoveDown, MoveRight},
+			[]notification.Kind{notification.RightMove, notification.WrongMove, notification.RightMove, notification.GameComplete},
+			"1 2 3 4 5 6 7 8 0", 2, 4, 0, true},
+		{"after completion", []Action{MoveRight, MoveRight, MoveLeft},
+			[]notification.Kind{notification.RightMove, notification.GameComplete, notification.GameOver},
+			"1 2 3 4 5 6 7 8 0", 2, 2, 0, true},
+	}
+
+	for _, test := range tests {
+		g := newGame(t, "1 2 3 4 5 6 0 7 8")
+
+		if kinds := play(g, test.actions); !sameKinds(kinds, test.kinds) {
+			t.Errorf("%v: events %v, want %v", test.name, kinds, test.kinds)
+		}
+
+		s := g.State()
+		if s.Board.String() != test.board {
+			t.Errorf("%v: board %v, want %v", test.name, s.Board, test.board)
+		}
+		if s.PlayerTotal != test.playerTotal || s.TotalMoves != test.totalMoves {
+			t.Errorf("%v: %v of %v moves right, want %v of %v", test.name, s.PlayerTotal, s.TotalMoves, test.playerTotal, test.totalMoves)
+		}
+		if s.SolvableMoves != test.solvableMoves || s.Complete != test.complete {
+			t.Errorf("%v: %v moves left and complete %v, want %v and %v", test.name, s.SolvableMoves, s.Complete, test.solvableMoves, test.complete)
+		}
+	}
+}
+
+func TestMoveAnotherSolution(t *testing.T) {
+	start := mustParse(t, "1 2 3 4 0 8 7 6 5")
+	g := newGame(t, start.String())
+	optimal := solver.NewOptimal(*board.Goal())
+
+	// an optimal first move other than the one planned
+	var other board.Board
+	for _, b := range optimal.FirstMoves(*start) {
+		if b != g.current.Value.(board.Board) {
+			other = b
+		}
+	}
+	if other == (board.Board{}) {
+		t.Fatalf("%v has a single optimal first move", start)
+	}
+
+	planned := g.solver
+	d, _ := board.Towards(*start, other)
+	if e := g.Move(d); e.Kind != notification.RightMove {
+		t.Fatalf("another optimal move: %v, want %v", e.Kind, notification.RightMove)
+	}
+
+	// hints follow the other solution to the goal without wrong moves
+	for !g.State().Complete {
+		hint := g.Hint()
+		g.hints = DefaultHints
+
+		if e := g.Move(hint.Direction); e.Kind != notification.RightMove && e.Kind != notification.GameComplete {
+			t.Fatalf("following hints: %v", e.Kind)
+		}
+	}
+
+	distance, _ := optimal.Distance(*start)
+	if s := g.State(); s.PlayerTotal != distance || s.TotalMoves != distance {
+		t.Errorf("%v of %v moves right, want %v of %v", s.PlayerTotal, s.TotalMoves, distance, distance)
+	}
+
+	// the plan of the first solver is left alone
+	if planned.Path.Len() != distance || planned.Path.Front().Value.(board.Board) == other {
+		t.Errorf("following another solution changed the previous plan")
+	}
+}
+
+func TestHint(t *testing.T) {
+	tests := []struct {
+		name    string
+		ready   bool
+		actions []Action
+		kinds   []notification.Kind
+		hints   int
+	}{
+		{"waiting for the plan", false, []Action{AskHint},
+			[]notification.Kind{notification.Wait}, DefaultHints},
+		{"first", true, []Action{AskHint},
+			[]notification.Kind{notification.Hint}, DefaultHints - 1},
+		{"all of them", true, []Action{AskHint, AskHint, AskHint, AskHint},
+			[]notification.Kind{notification.Hint, notification.Hint, notification.Hint, notification.NoHints}, 0},
+		{"after a wrong move", true, []Action{MoveUp, AskHint},
+			[]notification.Kind{notification.WrongMove, notification.Hint}, DefaultHints - 1},
+		{"hint after completion", true, []Action{MoveRight, MoveRight, AskHint},
+			[]notification.Kind{notification.RightMove, notification.GameComplete, notification.GameOver}, DefaultHints},
+	}
+
+	for _, test := range tests {
+		g := newGame(t, "1 2 3 4 5 6 0 7 8")
+		if !test.ready {
+			g = New(g.board, g.optimal, nil, solver.NewPresolver())
+		}
+
+		if kinds := play(g, test.actions); !sameKinds(kinds, test.kinds) {
+			t.Errorf("%v: events %v, want %v", test.name, kinds, test.kinds)
+		}
+		if hints := g.State().Hints; hints != test.hints {
+			t.Errorf("%v: %v hints left, want %v", test.name, hints, test.hints)
+		}
+	}
+
+	// a hint points along the plan, back down after a wrong move up
+	for _, test := range []struct {
+		actions []Action
+		want    board.Direction
+	}{
+		{nil, board.Right},
+		{[]Action{MoveUp}, board.Down},
+		{[]Action{MoveRight}, board.Right},
+	} {
+		g := newGame(t, "1 2 3 4 5 6 0 7 8")
+		play(g, test.actions)
+
+		if e := g.Hint(); e.Direction != test.want {
+			t.Errorf("hint after %v: %v, want %v", test.actions, e.Direction, test.want)
+		}
+	}
+}
+
+func TestUndo(t *testing.T) {
+	tests := []struct {
+		name    string
+		actions []Action
+		kinds   []notification.Kind
+
+		// state after the actions
+		board         string
+		playerTotal   int
+		solvableMoves int
+	}{
+		{"nothing", []Action{TakeBack},
+			[]notification.Kind{notification.NothingToUndo},
+			"1 2 3 4 5 6 0 7 8", 0, 2},
+		{"right move", []Action{MoveRight, TakeBack},
+			[]notification.Kind{notification.RightMove, notification.Undo},
+			"1 2 3 4 5 6 0 7 8", 1, 2},
+		{"wrong move", []Action{MoveUp, TakeBack},
+			[]notification.Kind{notification.WrongMove, notification.Undo},
+			"1 2 3 4 5 6 0 7 8", -1, 2},
+		{"two moves", []Action{MoveUp, MoveRight, TakeBack, TakeBack, TakeBack},
+			[]notification.Kind{notification.WrongMove, notification.WrongMove, notification.Undo, notification.Undo, notification.NothingToUndo},
+			"1 2 3 4 5 6 0 7 8", -2, 2},
+		{"completed game", []Action{MoveRight, MoveRight, TakeBack},
+			[]notification.Kind{notification.RightMove, notification.GameComplete, notification.GameOver},
+			"1 2 3 4 5 6 7 8 0", 2, 0},
+		{"and play on", []Action{MoveUp, TakeBack, MoveRight, MoveRight},
+			[]notification.Kind{notification.WrongMove, notification.Undo, notification.RightMove, notification.GameComplete},
+			"1 2 3 4 5 6 7 8 0", 1, 0},
+	}
+
+	for _, test := range tests {
+		g := newGame(t, "1 2 3 4 5 6 0 7 8")
+
+		if kinds := play(g, test.actions); !sameKinds(kinds, test.kinds) {
+			t.Errorf("%v: events %v, want %v", test.name, kinds, test.kinds)
+		}
+
+		s := g.State()
+		if s.Board.String() != test.board || s.PlayerTotal != test.playerTotal || s.SolvableMoves != test.solvableMoves {
+			t.Errorf("%v: board %v, %v right, %v moves left, want %v, %v and %v", test.name,
+				s.Board, s.PlayerTotal, s.SolvableMoves, test.board, test.playerTotal, test.solvableMoves)
+		}
+	}
+}
+
+func TestRunScript(t *testing.T) {
+	b := mustParse(t, "1 2 3 4 5 6 0 7 8")
+
+	presolver := solver.NewPresolver()
+	presolver.Heuristic = solver.Manhattan{}
+	g := New(*b, solver.NewOptimal(*board.Goal()), nil, presolver)
+
+	bus := notification.NewBus()
+	published := bus.Subscribe()
+	g.Bus = bus
+
+	r := run(g)
+
+	// the plan is solved elsewhere and arrives while the player may already be moving
+	plan := make(chan Plan)
+	go func() {
+		s := solver.NewWithHeuristic(b, solver.Manhattan{})
+		s.Solve()
+		plan <- Plan{Solver: s}
+	}()
+
+	steps := []struct {
+		action Action
+		kind   notification.Kind
+	}{
+		{MoveRight, notification.Wait},
+		{AskHint, notification.Wait},
+		{TakeBack, notification.NothingToUndo},
+	}
+	for _, step := range steps {
+		r.actions <- step.action
+		r.waitForEvent(t, step.kind)
+	}
+
+	r.plans <- <-plan
+	r.waitForEvent(t, notification.Ready)
+
+	r.notices <- notification.NewEvent(notification.Notice, "hello")
+	if rd := r.waitForEvent(t, notification.Notice); rd.event.Message != "hello" {
+		t.Errorf("notice %q, want %q", rd.event.Message, "hello")
+	}
+
+	steps = []struct {
+		action Action
+		kind   notification.Kind
+	}{
+		{MoveUp, notification.WrongMove},
+		{AskHint, notification.Hint},
+		{TakeBack, notification.Undo},
+		{Refresh, notification.Redraw},
+		{MoveLeft, notification.ImpossibleMove},
+		{MoveRight, notification.RightMove},
+		{MoveLeft, notification.WrongMove},
+		{MoveRight, notification.RightMove},
+		{AskHint, notification.Hint},
+		{MoveRight, notification.GameComplete},
+		{MoveUp, notification.GameOver},
+	}
+
+	var last rendered
+	for _, step := range steps {
+		r.actions <- step.action
+		last = r.waitForEvent(t, step.kind)
+	}
+
+	r.actions <- Quit
+	select {
+	case <-r.stopped:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run did not return after a Quit action")
+	}
+	bus.Close()
+
+	s := last.state
+	if !s.Complete || s.Board != *board.Goal() {
+		t.Errorf("final board %v, complete %v, want the goal", s.Board, s.Complete)
+	}
+	if s.PlayerTotal != 1 || s.TotalMoves != 5 || s.Hints != DefaultHints-2 {
+		t.Errorf("%v of %v moves right with %v hints left, want 1 of 5 with %v", s.PlayerTotal, s.TotalMoves, s.Hints, DefaultHints-2)
+	}
+
+	// listeners on the bus saw every move judged
+	counts := make(map[notification.Kind]int)
+	for e := range published.Events {
+		counts[e.Kind]++
+	}
+	if counts[notification.RightMove] != 2 || counts[notification.WrongMove] != 2 || counts[notification.GameComplete] != 1 {
+		t.Errorf("published %v right, %v wrong and %v complete, want 2, 2 and 1",
+			counts[notification.RightMove], counts[notification.WrongMove], counts[notification.GameComplete])
+	}
+}
@@ -0,0 +1,113 @@
+package locale
+
+// english is the catalog of the default language
+var english = &Catalog{
+	Language: "en",
+	one:      func(n int) bool { return n == 1 },
+	Messages: map[string]Message{
+		"welcome":         {Other: "Welcome to the game Puzzl!"},
+		"right-move":      {Other: "Woot! Right Move"},
+		"wrong-move":      {Other: "Oops! Wrong Move"},
+		"game-complete":   {Other: "Woot! You completed the game"},
+		"stage-complete":  {Other: "Woot! Stage done, on to the next"},
+		"impossible-move": {Other: "Unable to move there"},
+		"wait":            {Other: "Wait! Let bot solve it first"},
+		"ready":           {Other: "OK! You can play now"},
+		"quit":            {Other: "Press ESC key to quit"},
+		"no-hints":        {Other: "No more hints my friend."},
+		"nothing-to-hint": {Other: "Nothing left to hint."},
+		"undo":            {Other: "Move taken back"},
+		"nothing-to-undo": {Other: "Nothing to undo"},
+		"hint": {
+			One:   "Hint #%[2]v - move %[3]v side, %[1]v hint left",
+			Other: "Hint #%[2]v - move %[3]v side, %[1]v hints left",
+		},
+
+		"up":    {Other: "up"},
+		"down":  {Other: "down"},
+		"left":  {Other: "left"},
+		"right": {Other: "right"},
+
+		"easy":   {Other: "easy"},
+		"medium": {Other: "medium"},
+		"hard":   {Other: "hard"},
+		"expert": {Other: "expert"},
+
+		"game-score":   {Other: "GAME SCORE"},
+		"player-moves": {Other: "PLAYER MOVES"},
+		"solvable-in":  {Other: "SOLVABLE IN"},
+
+		"too-small": {Other: "Terminal too small, the game needs %[1]vx%[2]v"},
+
+		"help-title":      {Other: "Keys"},
+		"help-move-blank": {Other: "Move the blank %v"},
+		"help-move-tile":  {Other: "Move a tile %v"},
+		"help-hint":       {Other: "Ask for a hint"},
+		"help-undo":       {Other: "Take back a move"},
+		"help-history":    {Other: "Show or hide the history"},
+		"help-help":       {Other: "Show or hide this help"},
+		"help-quit":       {Other: "Quit the game"},
+
+		"key-esc":       {Other: "Esc"},
+		"key-enter":     {Other: "Enter"},
+		"key-space":     {Other: "Space"},
+		"key-tab":       {Other: "Tab"},
+		"key-backspace": {Other: "Backspace"},
+	},
+}
+
+// spanish is the catalog of the Spanish language
+var spanish = &Catalog{
+	Language: "es",
+	one:      func(n int) bool { return n == 1 },
+	Messages: map[string]Message{
+		"welcome":         {Other: "¡Bienvenido al juego Puzzl!"},
+		"right-move":      {Other: "¡Bien! Movimiento correcto"},
+		"wrong-move":      {Other: "¡Uy! Movimiento incorrecto"},
+		"game-complete":   {Other: "¡Bien! Completaste el juego"},
+		"stage-complete":  {Other: "¡Bien! Etapa lista, a la siguiente"},
+		"impossible-move": {Other: "No se puede mover ahí"},
+		"wait":            {Other: "¡Espera! Deja que el bot lo resuelva"},
+		"ready":           {Other: "¡Listo! Ya puedes jugar"},
+		"quit":            {Other: "Pulsa ESC para salir"},
+		"no-hints":        {Other: "No quedan más pistas, amigo."},
+		"nothing-to-hint": {Other: "No queda nada que sugerir."},
+		"undo":            {Other: "Movimiento deshecho"},
+		"nothing-to-undo": {Other: "Nada que deshacer"},
+		"hint": {
+			One:   "Pista #%[2]v - mueve hacia %[3]v, queda %[1]v pista",
+			Other: "Pista #%[2]v - mueve hacia %[3]v, quedan %[1]v pistas",
+		},
+
+		"up":    {Other: "arriba"},
+		"down":  {Other: "abajo"},
+		"left":  {Other: "la izquierda"},
+		"right": {Other: "la derecha"},
+
+		"easy":   {Other: "fácil"},
+		"medium": {Other: "media"},
+		"hard":   {Other: "difícil"},
+		"expert": {Other: "experto"},
+
+		"game-score":   {Other: "PUNTUACIÓN"},
+		"player-moves": {Other: "MOVIMIENTOS"},
+		"solvable-in":  {Other: "RESOLUBLE EN"},
+
+		"too-small": {Other: "Terminal demasiado pequeña, el juego necesita %[1]vx%[2]v"},
+
+		"help-title":      {Other: "Teclas"},
+		"help-move-blank": {Other: "Mover el hueco hacia %v"},
+		"help-move-tile":  {Other: "Mover una ficha hacia %v"},
+		"help-hint":       {Other: "Pedir una pista"},
+		"help-undo":       {Other: "Deshacer un movimiento"},
+		"help-history":    {Other: "Mostrar u ocultar el historial"},
+		"help-help":       {Other: "Mostrar u ocultar esta ayuda"},
+		"help-quit":       {Other: "Salir del juego"},
+
+		"key-esc":       {Other: "Esc"},
+		"key-enter":     {Other: "Intro"},
+		"key-space":     {Other: "Espacio"},
+		"key-tab":       {Other: "Tab"},
+		"key-backspace": {Other: "Retroceso"},
+	},
+}
@@ -0,0 +1,118 @@
+// Package locale translates the text shown to players
+//
+// Messages are looked up by key in the catalog of a language. Their
+// arguments are formatted with fmt verbs, which may be indexed like %[2]v
+// for languages needing another order, and the first argument chooses
+// between the singular and plural form of messages having both.
+package locale
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultLanguage is used when no catalog matches the requested language
+const DefaultLanguage string = "en"
+
+// Key is a message key given as an argument, it is translated itself
+// before being formatted into the message
+type Key string
+
+// Message is the text of a message in a language
+type Message struct {
+	// Other is the text for every count except the singular one
+	Other string
+
+	// One is the optional singular text
+	One string
+}
+
+// Catalog holds the messages of a language
+type Catalog struct {
+	Language string
+	Messages map[string]Message
+
+	// one returns whether a count takes the singular form
+	one func(n int) bool
+}
+
+// Catalogs lists the available catalogs by language
+var Catalogs = map[string]*Catalog{
+	"en": english,
+	"es": spanish,
+}
+
+// Lookup returns the catalog for a language like es, es_ES or es_ES.UTF-8,
+// the default language catalog when there is none for it
+func Lookup(language string) *Catalog {
+	language = strings.ToLower(language)
+	if i := strings.IndexAny(language, "_-.@"); i >= 0 {
+		language = language[:i]
+	}
+
+	if c, ok := Catalogs[language]; ok {
+		return c
+	}
+
+	return Catalogs[DefaultLanguage]
+}
+
+// FromEnv returns the language of the environment, from LC_ALL,
+// LC_MESSAGES or LANG in that order
+func FromEnv() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+
+	return DefaultLanguage
+}
+
+// Languages returns the sorted languages having a catalog
+func Languages() []string {
+	var languages []string
+	for l := range Catalogs {
+		languages = append(languages, l)
+	}
+	sort.Strings(languages)
+
+	return languages
+}
+
+// Text returns the translated message for a key, formatted with the arguments
+// Unknown keys fall back to the default language, then to the key itself.
+func (c *Catalog) Text(key string, args ...interface{}) string {
+	m, ok := c.Messages[key]
+	one := c.one
+	if !ok {
+		m, ok = Catalogs[DefaultLanguage].Messages[key]
+		one = Catalogs[DefaultLanguage].one
+	}
+	if !ok {
+		m = Message{Other: key}
+	}
+
+	text := m.Other
+	if m.One != "" && len(args) > 0 {
+		if n, isInt := args[0].(int); isInt && one(n) {
+			text = m.One
+		}
+	}
+
+	if len(args) == 0 {
+		return text
+	}
+
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		if k, isKey := arg.(Key); isKey {
+			arg = c.Text(string(k))
+		}
+		values[i] = arg
+	}
+
+	return fmt.Sprintf(text, values...)
+}
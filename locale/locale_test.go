@@ -0,0 +1,36 @@
+package locale
+
+import (
+	"testing"
+)
+
+func TestPlural(t *testing.T) {
+	tests := []struct {
+		language string
+		left     int
+		want     string
+	}{
+		{"en", 0, "Hint #3 - move up side, 0 hints left"},
+		{"en", 1, "Hint #2 - move up side, 1 hint left"},
+		{"en", 2, "Hint #1 - move up side, 2 hints left"},
+		{"es", 0, "Pista #3 - mueve hacia arriba, quedan 0 pistas"},
+		{"es", 1, "Pista #2 - mueve hacia arriba, queda 1 pista"},
+		{"es", 2, "Pista #1 - mueve hacia arriba, quedan 2 pistas"},
+	}
+
+	for _, test := range tests {
+		if text := Catalogs[test.language].Text("hint", test.left, 3-test.left, Key("up")); text != test.want {
+			t.Errorf("%v with %v hints left: %q, want %q", test.language, test.left, text, test.want)
+		}
+	}
+}
+
+func TestCatalogsComplete(t *testing.T) {
+	for _, language := range Languages() {
+		for key := range Catalogs[DefaultLanguage].Messages {
+			if _, ok := Catalogs[language].Messages[key]; !ok {
+				t.Errorf("%v: no message for %q", language, key)
+			}
+		}
+	}
+}
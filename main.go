@@ -1,22 +1,227 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"github.com/pravj/puzzl/batch"
 	"github.com/pravj/puzzl/board"
+	"github.com/pravj/puzzl/game"
+	"github.com/pravj/puzzl/locale"
 	"github.com/pravj/puzzl/notification"
 	"github.com/pravj/puzzl/solver"
 	"github.com/pravj/puzzl/surface"
+	"github.com/pravj/puzzl/theme"
+	"os"
+	"runtime"
+)
+
+// command line options of the game
+var (
+	cacheFile = flag.String("cache", "", "file to keep solved boards across game sessions")
+	staged    = flag.Bool("stages", false, "solve the top row first and then the rest of the board")
+	checkName = flag.String("check-heuristic", "", "verify a heuristic for admissibility and consistency on small boards, then exit")
+
+	analyzeShapeName = flag.String("analyze", "", "explore every configuration of a board shape like 3x3 (up to 12 cells), then exit")
+	csvFile          = flag.String("csv", "", "file to write the analysis as CSV")
+	jsonFile         = flag.String("json", "", "file to write the analysis as JSON")
+
+	boardText     = flag.String("board", "", "board to use instead of a random one, tile values row by row with 0 as blank")
+	heuristicName = flag.String("heuristic", "misplaced", "heuristic used by the solver, comma separated names take the largest")
+	tieName       = flag.String("tie", solver.DefaultTieBreak.String(), "order of nodes with equal f-cost: high-g, low-h, fifo, lifo or none")
+	dotFile       = flag.String("dot", "", "write the solver's search tree for the board as a DOT graph to a file (- for stdout), then exit")
+	dotDepth      = flag.Int("depth", 0, "depth cap of the DOT graph, 0 for no cap")
+
+	batchFile   = flag.String("batch", "", "solve the boards of a file (- for stdin), one per line, then exit")
+	batchOut    = flag.String("out", "-", "file to write the batch results (- for stdout)")
+	batchFormat = flag.String("format", batch.JSONL, "format of the batch results: jsonl or csv")
+	workers     = flag.Int("workers", runtime.NumCPU(), "number of boards solved at the same time")
+
+	benchSet      = flag.String("bench", "", "benchmark the solver on an instance set (korf100, 8puzzle) or a file of boards, then exit")
+	algorithmName = flag.String("algorithm", "idastar", "search algorithm of the benchmark: astar (3x3 only) or idastar")
+	korfNotation  = flag.Bool("korf", false, "boards of the benchmark file have the blank tile first in the goal, like Korf's instances")
+
+	verifyMoves = flag.String("verify", "", "check that moves of the blank tile (U, D, L, R) solve the -board, then exit")
+	goalText    = flag.String("goal", "", "goal board of -verify, tiles in order with the blank tile last by default")
+	showSteps   = flag.Bool("steps", false, "print the board after every move replayed by -verify")
+
+	levelName = flag.String("difficulty", "", "generate a board of a difficulty: easy, medium, hard or expert")
+	rateOnly  = flag.Bool("rate", false, "print the difficulty rating of the board, then exit")
+
+	eventsFile = flag.String("events", "", "file to log every game event to, one per line")
+	language   = flag.String("lang", locale.FromEnv(), "language of the game, like en or es (from LC_ALL, LC_MESSAGES or LANG by default)")
+	labelSpec  = flag.String("labels", "numbers", "tile labels: numbers, letters or comma separated glyphs for the tiles in order")
+	themeName  = flag.String("theme", theme.DefaultTheme, "colors of the game: classic, monochrome, high-contrast, colorblind, tritan or a theme file")
+	colorMode  = flag.String("colors", theme.ModeFromEnv().String(), "colors the terminal shows: 16, 256 or truecolor (from COLORTERM and TERM by default)")
+	keysName   = flag.String("keys", surface.DefaultBindings, "key bindings: arrows, wasd, vim or a key binding file")
+	invertKeys = flag.Bool("invert", false, "direction keys move a tile into the blank instead of moving the blank")
 )
 
 func main() {
+	flag.Parse()
+
+	if *checkName != "" {
+		os.Exit(checkHeuristic(*checkName))
+	}
+
+	if *analyzeShapeName != "" {
+		os.Exit(analyzeShape(*analyzeShapeName, *csvFile, *jsonFile))
+	}
+
+	// exact distances to the goal, used to rate boards and to judge player moves
+	goalOptimal := solver.NewOptimal(*board.Goal())
+
 	gameBoard := board.New()
+	if *boardText != "" {
+		b, err := board.Parse(*boardText)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		gameBoard = b
+	} else if *levelName != "" {
+		level, err := solver.ParseLevel(*levelName)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		b, _ := solver.Generate(level, goalOptimal, solver.Manhattan{}, generateTries)
+		gameBoard = &b
+	}
+
+	if *rateOnly {
+		os.Exit(printRating(*gameBoard, goalOptimal))
+	}
+
+	if *verifyMoves != "" {
+		if *boardText == "" {
+			fmt.Fprintln(os.Stderr, "-verify needs the starting -board")
+			os.Exit(2)
+		}
+		os.Exit(verifySolution(gameBoard, *goalText, *verifyMoves, *showSteps))
+	}
+
+	gameHeuristic, err := solver.LookupHeuristic(*heuristicName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	gameTieBreak, err := solver.ParseTieBreak(*tieName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
-	gameNotification := notification.New()
+	if *benchSet != "" {
+		os.Exit(runBenchmark(*benchSet, *algorithmName, gameHeuristic, *korfNotation))
+	}
+
+	if *batchFile != "" {
+		opts := batch.Options{Workers: *workers, Heuristic: gameHeuristic, TieBreak: gameTieBreak, Format: *batchFormat}
+		os.Exit(solveBatch(*batchFile, *batchOut, opts))
+	}
+
+	if *dotFile != "" {
+		os.Exit(writeSearchTree(*dotFile, gameBoard, gameHeuristic, gameTieBreak, *dotDepth))
+	}
+
+	gameLabels, err := surface.ParseLabels(*labelSpec, board.SIZE*board.SIZE)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	gameTheme, err := theme.Open(*themeName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	gameColors, err := theme.ParseMode(*colorMode)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	gameKeys, err := surface.OpenBindings(*keysName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *invertKeys {
+		inverted := *gameKeys
+		inverted.Invert = true
+		gameKeys = &inverted
+	}
+
+	gameBus := notification.NewBus()
+
+	gameCatalog := locale.Lookup(*language)
+
+	loggerDone, err := logEvents(*eventsFile, gameBus, gameCatalog)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	gameCache := solver.NewCache(solver.DefaultCacheSize)
+	if *cacheFile != "" {
+		if err := gameCache.Load(*cacheFile); err != nil && !os.IsNotExist(err) {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	var gameStages []solver.PartialGoal
+	var gameSolver *solver.Solver
+	var gameOptimal *solver.Optimal
+
+	if *staged {
+		gameStages, err = solver.TeachingStagesFor(board.SIZE, board.SIZE)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		gameSolver = solver.NewPartial(gameBoard, gameStages[0])
+		gameOptimal = solver.NewOptimal(gameStages[0].States(*gameBoard)...)
+	} else {
+		gameSolver = solver.NewWithHeuristic(gameBoard, gameHeuristic)
+		gameOptimal = goalOptimal
+	}
+	gameSolver.Cache = gameCache
+	gameSolver.TieBreak = gameTieBreak
+
+	plans := make(chan game.Plan, 1)
 
-	gameSolver := solver.New(gameBoard)
 	go func() {
+		// discovers optimal distances up to the board, used to judge player moves
+		gameOptimal.Distance(*gameBoard)
+		rating := solver.Rate(goalOptimal, *gameBoard, solver.Manhattan{})
+
 		gameSolver.Solve()
-		gameNotification.Tunnel <- notification.WelcomeMessage
+		plans <- game.Plan{Solver: gameSolver, Rating: rating}
 	}()
 
-	surface.New(gameBoard, gameSolver, gameNotification)
+	// plans ahead of the player are worked out the same way as the first one
+	gamePresolver := solver.NewPresolver()
+	gamePresolver.Cache = gameCache
+	gamePresolver.Heuristic = gameHeuristic
+	gamePresolver.TieBreak = gameTieBreak
+
+	gameEngine := game.New(*gameBoard, gameOptimal, gameStages, gamePresolver)
+	gameEngine.Bus = gameBus
+
+	surface.New(gameEngine, plans, nil, surface.Options{Catalog: gameCatalog, Labels: gameLabels, Theme: gameTheme, Colors: gameColors, Keys: gameKeys})
+
+	// ends every subscription, waiting for the event log to be written
+	gameBus.Close()
+	<-loggerDone
+
+	if *cacheFile != "" {
+		if err := gameCache.Save(*cacheFile); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
 }
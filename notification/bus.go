@@ -0,0 +1,146 @@
+package notification
+
+import (
+	"sync"
+)
+
+// Bus delivers every published event to all of its subscribers
+//
+// Publishing never blocks and never drops an event, each subscriber has its
+// own queue without a limit, handed over by a goroutine of its own as fast
+// as the subscriber receives. Closing the bus lets every subscriber receive
+// what was published before, then closes its channel, exactly once.
+type Bus struct {
+	mutex       sync.Mutex
+	subscribers map[*Subscription]bool
+	closed      bool
+}
+
+// Subscription is the stream of events received by a single subscriber
+type Subscription struct {
+	// Events is closed when the subscriber leaves or once the bus is closed
+	// and every event before is received
+	Events <-chan Event
+
+	events chan Event
+	bus    *Bus
+
+	// published events not received yet, wake tells the delivering goroutine about new ones
+	mutex   sync.Mutex
+	pending []Event
+	ended   bool
+	wake    chan struct{}
+
+	// closed when the subscriber leaves, the events not received yet are thrown away
+	left  chan struct{}
+	leave sync.Once
+}
+
+// NewBus returns pointer to a new Bus instance
+func NewBus() *Bus {
+	return &Bus{subscribers: make(map[*Subscription]bool)}
+}
+
+// Subscribe returns a new subscription receiving every event published from now on
+// Subscribing to a closed bus gives an already closed subscription.
+func (b *Bus) Subscribe() *Subscription {
+	events := make(chan Event)
+	sub := &Subscription{Events: events, events: events, bus: b, wake: make(chan struct{}, 1), left: make(chan struct{})}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		close(events)
+		return sub
+	}
+	b.subscribers[sub] = true
+
+	go sub.deliver()
+
+	return sub
+}
+
+// Publish hands an event over to every subscriber, nothing is done once closed
+func (b *Bus) Publish(e Event) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for sub := range b.subscribers {
+		sub.push(e, false)
+	}
+}
+
+// Close ends every subscription once its events are received,
+// it is safe to call more than once
+func (b *Bus) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+
+	for sub := range b.subscribers {
+		sub.push(Event{}, true)
+		delete(b.subscribers, sub)
+	}
+}
+
+// Unsubscribe ends the subscription right away, it is safe to call more
+// than once and after the bus is closed
+func (s *Subscription) Unsubscribe() {
+	s.bus.mutex.Lock()
+	delete(s.bus.subscribers, s)
+	s.bus.mutex.Unlock()
+
+	s.leave.Do(func() { close(s.left) })
+}
+
+// push queues an event for the subscriber, or marks the end of the events
+func (s *Subscription) push(e Event, end bool) {
+	s.mutex.Lock()
+	if end {
+		s.ended = true
+	} else {
+		s.pending = append(s.pending, e)
+	}
+	s.mutex.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliver hands the queued events over to the subscriber, oldest first,
+// until the subscriber leaves or the bus is closed
+func (s *Subscription) deliver() {
+	defer close(s.events)
+
+	for {
+		s.mutex.Lock()
+		events, ended := s.pending, s.ended
+		s.pending = nil
+		s.mutex.Unlock()
+
+		for _, e := range events {
+			select {
+			case s.events <- e:
+			case <-s.left:
+				return
+			}
+		}
+
+		if ended {
+			return
+		}
+
+		select {
+		case <-s.wake:
+		case <-s.left:
+			return
+		}
+	}
+}
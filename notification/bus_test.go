@@ -0,0 +1,104 @@
+package notification
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// receive collects the events of a subscription until it is closed
+func receive(t *testing.T, sub *Subscription) []Event {
+	var events []Event
+
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case e, ok := <-sub.Events:
+			if !ok {
+				return events
+			}
+			events = append(events, e)
+		case <-timeout:
+			t.Fatalf("subscription not closed after %v events", len(events))
+		}
+	}
+}
+
+func TestBusSubscribers(t *testing.T) {
+	const n = 1000
+
+	bus := NewBus()
+	subs := []*Subscription{bus.Subscribe(), bus.Subscribe(), bus.Subscribe()}
+
+	// nobody receives while publishing, nothing may be dropped
+	for i := 0; i < n; i++ {
+		bus.Publish(NewEvent(Notice, fmt.Sprint(i)))
+	}
+	bus.Close()
+
+	for s, sub := range subs {
+		events := receive(t, sub)
+		if len(events) != n {
+			t.Errorf("subscriber %v: %v events, want %v", s, len(events), n)
+			continue
+		}
+
+		for i, e := range events {
+			if e.Message != fmt.Sprint(i) {
+				t.Errorf("subscriber %v: event %v is %q, want them in order", s, i, e.Message)
+				break
+			}
+		}
+	}
+}
+
+func TestBusUnsubscribeWhilePublishing(t *testing.T) {
+	const n = 1000
+
+	bus := NewBus()
+	leaving, staying := bus.Subscribe(), bus.Subscribe()
+
+	published := make(chan bool)
+	go func() {
+		for i := 0; i < n; i++ {
+			bus.Publish(NewEvent(Notice, fmt.Sprint(i)))
+		}
+		close(published)
+	}()
+
+	// the leaving subscriber receives a few events, the rest are thrown away
+	for i := 0; i < 10; i++ {
+		<-leaving.Events
+	}
+	leaving.Unsubscribe()
+	leaving.Unsubscribe()
+	receive(t, leaving)
+
+	<-published
+	bus.Close()
+
+	if events := receive(t, staying); len(events) != n {
+		t.Errorf("staying subscriber: %v events, want %v", len(events), n)
+	}
+}
+
+func TestBusCloseTwice(t *testing.T) {
+	bus := NewBus()
+	sub := bus.Subscribe()
+
+	bus.Publish(NewEvent(Notice, "before"))
+	bus.Close()
+	bus.Close()
+
+	// nothing is published once closed
+	bus.Publish(NewEvent(Notice, "after"))
+
+	if events := receive(t, sub); len(events) != 1 || events[0].Message != "before" {
+		t.Errorf("events %v, want only the one before closing", events)
+	}
+	sub.Unsubscribe()
+
+	if events := receive(t, bus.Subscribe()); len(events) != 0 {
+		t.Errorf("subscription to a closed bus: %v events, want none", len(events))
+	}
+}
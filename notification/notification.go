@@ -1,25 +1,136 @@
 // Package notification implements inter process messaging for the game
 package notification
 
-// Notification messages to show in the game
+import (
+	"fmt"
+	"github.com/pravj/puzzl/board"
+	"time"
+)
+
+// Keys of the notification messages, translated by package locale
+const (
+	WelcomeMessage        string = "welcome"
+	RightMoveMessage      string = "right-move"
+	WrongMoveMessage      string = "wrong-move"
+	GameCompleteMessage   string = "game-complete"
+	StageCompleteMessage  string = "stage-complete"
+	ImpossibleMoveMessage string = "impossible-move"
+	WaitMessage           string = "wait"
+	ReadyToPlayMessage    string = "ready"
+	QuitMessage           string = "quit"
+	NoHintsMessage        string = "no-hints"
+	NothingToHintMessage  string = "nothing-to-hint"
+	UndoMessage           string = "undo"
+	NothingToUndoMessage  string = "nothing-to-undo"
+	HintMessage           string = "hint"
+)
+
+// Kind represents what happened in the game
+type Kind int
+
+// Kinds of events
+const (
+	Welcome Kind = iota
+	RightMove
+	WrongMove
+	ImpossibleMove
+	Wait
+	StageComplete
+	GameComplete
+	Hint
+	NoHints
+	Undo
+	NothingToUndo
+	GameOver
+
+	// Ready tells that the board is solved and moves are judged from now on
+	Ready
+
+	// Notice carries a message from outside of the game
+	Notice
+
+	// Redraw asks renderers to show the unchanged game again
+	Redraw
+)
+
+// kindNames holds the name of each kind
+var kindNames = [...]string{"welcome", "right-move", "wrong-move", "impossible-move", "wait", "stage-complete", "game-complete", "hint", "no-hints", "undo", "nothing-to-undo", "game-over", "ready", "notice", "redraw"}
+
+// String returns the name of a kind
+func (k Kind) String() string {
+	if k < Welcome || k > Redraw {
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+
+	return kindNames[k]
+}
+
+// Severity represents how an event should be presented
+type Severity int
+
+// Severities of events, from neutral to bad news
 const (
-	WelcomeMessage        string = "Welcome to the game Puzzl!"
-	RightMoveMessage      string = "Woot! Right Move"
-	WrongMoveMessage      string = "Oops! Wrong Move"
-	GameCompleteMessage   string = "Woot! You completed the game"
-	ImpossibleMoveMessage string = "Unable to move there"
-	WaitMessage           string = "Wait! Let bot solve it first"
-	ReadyToPlayMessage    string = "OK! You can play now"
-	QuitMessage           string = "Press ESC key to quit"
+	Info Severity = iota
+	Success
+	Warning
+	Error
 )
 
-// Notification struct
-// It consists the channel used for internal notification communication
-type Notification struct {
-	Tunnel chan string
+// severityNames holds the name of each severity
+var severityNames = [...]string{"info", "success", "warning", "error"}
+
+// String returns the name of a severity
+func (s Severity) String() string {
+	if s < Info || s > Error {
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+
+	return severityNames[s]
 }
 
-// New returns pointer to a Notification struct
-func New() *Notification {
-	return &Notification{Tunnel: make(chan string)}
+// severities holds the severity of each kind, Info for the rest
+var severities = map[Kind]Severity{
+	RightMove:      Success,
+	StageComplete:  Success,
+	GameComplete:   Success,
+	WrongMove:      Error,
+	ImpossibleMove: Error,
+	NoHints:        Error,
+	NothingToUndo:  Error,
+	GameOver:       Error,
+	Wait:           Warning,
+}
+
+// Event is something that happened in the game, along with a message to show
+type Event struct {
+	Kind     Kind
+	Severity Severity
+
+	// Message is the key of the message in the locale catalogs, or the text
+	// itself for notices from outside, formatted with Args
+	Message string
+	Args    []interface{}
+
+	// direction of the blank tile for moves, undone moves and hints
+	Direction board.Direction
+
+	// change of the player's score caused by a move
+	ScoreDelta int
+
+	Time time.Time
+}
+
+// NewEvent returns an event of a kind with its usual severity, happening now
+func NewEvent(kind Kind, message string) Event {
+	return Event{Kind: kind, Severity: severities[kind], Message: message, Time: time.Now()}
+}
+
+// String returns the event as a log line, with the untranslated message
+func (e Event) String() string {
+	line := fmt.Sprintf("%v %v %v: %v", e.Time.Format("15:04:05"), e.Severity, e.Kind, e.Message)
+	if len(e.Args) > 0 {
+		line += fmt.Sprint(" ", e.Args)
+	}
+
+	return line
 }
@@ -0,0 +1,116 @@
+package notification
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Defaults of a Queue
+const (
+	// DefaultDuration is how long a notification stays on screen
+	DefaultDuration time.Duration = 4 * time.Second
+
+	// DefaultHistory is the number of past notifications kept
+	DefaultHistory int = 50
+)
+
+// Queue keeps the notifications to show, each for a limited time,
+// along with a history of the past ones
+type Queue struct {
+	Duration time.Duration
+
+	// unexpired notifications, oldest first
+	active []Event
+
+	history []Event
+	limit   int
+}
+
+// NewQueue returns pointer to a Queue instance showing notifications
+// for a duration and remembering up to limit of them
+func NewQueue(duration time.Duration, limit int) *Queue {
+	return &Queue{Duration: duration, limit: limit}
+}
+
+// Push adds a notification, events without a message are ignored
+func (q *Queue) Push(e Event) {
+	if e.Message == "" {
+		return
+	}
+
+	q.active = append(q.active, e)
+
+	q.history = append(q.history, e)
+	if len(q.history) > q.limit {
+		q.history = q.history[len(q.history)-q.limit:]
+	}
+}
+
+// Current returns the newest notification which has not expired yet,
+// false when there is nothing to show
+func (q *Queue) Current(now time.Time) (Event, bool) {
+	for len(q.active) > 0 && now.Sub(q.active[0].Time) >= q.Duration {
+		q.active = q.active[1:]
+	}
+
+	if len(q.active) == 0 {
+		return Event{}, false
+	}
+
+	return q.active[len(q.active)-1], true
+}
+
+// History returns up to n of the latest notifications, oldest first
+func (q *Queue) History(n int) []Event {
+	if n > len(q.history) {
+		n = len(q.history)
+	}
+
+	return q.history[len(q.history)-n:]
+}
+
+// Wrap splits text into lines of at most width characters, breaking at spaces
+// when possible
+func Wrap(text string, width int) []string {
+	var lines []string
+
+	for _, word := range strings.Fields(text) {
+		// words longer than a line are broken anywhere
+		for utf8.RuneCountInString(word) > width {
+			runes := []rune(word)
+			lines = append(lines, string(runes[:width]))
+			word = string(runes[width:])
+		}
+
+		last := len(lines) - 1
+		if last >= 0 && lines[last] != "" && utf8.RuneCountInString(lines[last])+1+utf8.RuneCountInString(word) <= width {
+			lines[last] += " " + word
+		} else {
+			lines = append(lines, word)
+		}
+	}
+
+	return lines
+}
+
+// Scroll returns the window of width characters into text to show
+// after some time, moving one character for every step
+// Text fitting into the width is returned as it is.
+func Scroll(text string, width int, elapsed, step time.Duration) string {
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
+	}
+
+	// the text runs around with a gap of a few spaces
+	loop := append(runes, []rune("   ")...)
+	offset := int(elapsed/step) % len(loop)
+
+	window := make([]rune, width)
+	for i := range window {
+		window[i] = loop[(offset+i)%len(loop)]
+	}
+
+	return string(window)
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"fmt"
+	"github.com/pravj/puzzl/board"
+	"github.com/pravj/puzzl/solver"
+)
+
+// generateTries is the number of random boards tried for a difficulty level
+const generateTries int = 200
+
+// printRating prints the difficulty of a board along with its parts,
+// returns the exit status for the command
+func printRating(b board.Board, o *solver.Optimal) int {
+	d := solver.Rate(o, b, solver.Manhattan{})
+
+	fmt.Printf("board %v\n", b)
+	fmt.Printf("difficulty %v (%.1f of 100)\n", d.Level, d.Score)
+	fmt.Printf("optimal length %v, %v optimal solutions (branching %.2f)\n", d.Length, d.Solutions, d.Branching)
+	fmt.Printf("%v solutions two moves longer, heuristic error %v\n", d.NearOptimal, d.HeuristicError)
+
+	return 0
+}
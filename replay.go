@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"github.com/pravj/puzzl/board"
+	"github.com/pravj/puzzl/verify"
+	"os"
+)
+
+// verifySolution replays the moves from a board, printing every step when
+// asked, and reports whether they solve it optimally,
+// returns the exit status for the command
+func verifySolution(start *board.Board, goalText, moves string, steps bool) int {
+	goal := board.Goal()
+	if goalText != "" {
+		b, err := board.Parse(goalText)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		goal = b
+	}
+
+	if steps {
+		fmt.Printf("%4v  %v\n", 0, start)
+		verify.Replay(*start, moves, func(step int, b board.Board) {
+			fmt.Printf("%4v  %v\n", step, b)
+		})
+	}
+
+	result := verify.Verify(*start, *goal, moves)
+	fmt.Println(result.Summary())
+
+	if !result.Valid() {
+		return 1
+	}
+
+	return 0
+}
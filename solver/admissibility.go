@@ -0,0 +1,97 @@
+package solver
+
+import (
+	"fmt"
+	"github.com/pravj/puzzl/board"
+)
+
+// MaxViolations is the number of violating states a report keeps
+const MaxViolations int = 20
+
+// Violation describes a state where a heuristic breaks one of its guarantees
+type Violation struct {
+	State board.Grid
+
+	// Estimate is the heuristic value of the state, Bound is the value it exceeded,
+	// the exact distance for admissibility and neighbour estimate plus one for consistency
+	Estimate int
+	Bound    int
+
+	// neighbour state for a consistency violation
+	Neighbour *board.Grid
+}
+
+// String returns a human readable description of the violation
+func (v Violation) String() string {
+	if v.Neighbour != nil {
+		return fmt.Sprintf("inconsistent at %v: h=%v > %v via %v", v.State, v.Estimate, v.Bound, *v.Neighbour)
+	}
+
+	return fmt.Sprintf("inadmissible at %v: h=%v > distance %v", v.State, v.Estimate, v.Bound)
+}
+
+// Report holds the result of checking a heuristic on a board shape
+type Report struct {
+	Rows int
+	Cols int
+
+	States int
+
+	Inadmissible int
+	Inconsistent int
+
+	// first MaxViolations violating states
+	Violations []Violation
+}
+
+// Admissible returns whether the heuristic never overestimated the distance
+func (r Report) Admissible() bool {
+	return r.Inadmissible == 0
+}
+
+// Consistent returns whether the heuristic never dropped by more than one per move
+func (r Report) Consistent() bool {
+	return r.Inconsistent == 0
+}
+
+// CheckHeuristic exhaustively verifies a heuristic against exact
+// breadth first search distances for every state of a rows*cols board
+func CheckHeuristic(h Heuristic, rows, cols int) Report {
+	report := Report{Rows: rows, Cols: cols}
+
+	add := func(v Violation) {
+		if len(report.Violations) < MaxViolations {
+			v.State = copyGrid(v.State)
+			report.Violations = append(report.Violations, v)
+		}
+	}
+
+	Explore(rows, cols).Each(func(g board.Grid, distance int) {
+		report.States++
+
+		estimate := h.Estimate(g)
+		if estimate > distance {
+			report.Inadmissible++
+			add(Violation{State: g, Estimate: estimate, Bound: distance})
+		}
+
+		for _, n := range g.Neighbours() {
+			if bound := h.Estimate(n) + 1; estimate > bound {
+				report.Inconsistent++
+
+				neighbour := n
+				add(Violation{State: g, Estimate: estimate, Bound: bound, Neighbour: &neighbour})
+			}
+		}
+	})
+
+	return report
+}
+
+// copyGrid returns a grid not sharing its tiles with the given one
+func copyGrid(g board.Grid) board.Grid {
+	tiles := make([]int, len(g.Tiles))
+	copy(tiles, g.Tiles)
+
+	return board.Grid{Rows: g.Rows, Cols: g.Cols, Tiles: tiles}
+}
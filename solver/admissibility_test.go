@@ -0,0 +1,58 @@
+package solver
+
+import (
+	"github.com/pravj/puzzl/board"
+	"testing"
+)
+
+// misplacedWithBlank is the misplaced tile count that counts the blank tile too
+var misplacedWithBlank = HeuristicFunc(func(g board.Grid) int {
+	var h int
+	for i, v := range g.Tiles {
+		if v != (i+1)%len(g.Tiles) {
+			h++
+		}
+	}
+
+	return h
+})
+
+// evenManhattan is the Manhattan distance rounded down to an even number,
+// never too high but dropping by two over a single move
+var evenManhattan = HeuristicFunc(func(g board.Grid) int {
+	h := Manhattan{}.Estimate(g)
+	return h - h%2
+})
+
+func TestCheckHeuristic(t *testing.T) {
+	tests := []struct {
+		name       string
+		h          Heuristic
+		admissible bool
+		consistent bool
+	}{
+		{"misplaced tiles", MisplacedTiles{}, true, true},
+		{"Manhattan", Manhattan{}, true, true},
+		{"misplaced tiles and the blank", misplacedWithBlank, false, false},
+		{"even Manhattan", evenManhattan, true, false},
+	}
+
+	for _, test := range tests {
+		for _, shape := range [][2]int{{2, 3}, {3, 3}} {
+			r := CheckHeuristic(test.h, shape[0], shape[1])
+
+			if r.Admissible() != test.admissible || r.Consistent() != test.consistent {
+				t.Errorf("%v on %vx%v: admissible %v, consistent %v, want %v and %v", test.name, shape[0], shape[1], r.Admissible(), r.Consistent(), test.admissible, test.consistent)
+			}
+			if r.States == 0 || len(r.Violations) > MaxViolations || (r.Inadmissible+r.Inconsistent > 0) != (len(r.Violations) > 0) {
+				t.Errorf("%v on %vx%v: %v states, %v violations kept", test.name, shape[0], shape[1], r.States, len(r.Violations))
+			}
+
+			for _, v := range r.Violations {
+				if v.Estimate <= v.Bound {
+					t.Errorf("%v on %vx%v: violation %v within its bound", test.name, shape[0], shape[1], v)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package solver
+
+import (
+	"bufio"
+	"container/list"
+	"fmt"
+	"github.com/pravj/puzzl/board"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultCacheSize is the number of solved boards a cache keeps by default
+const DefaultCacheSize int = 100000
+
+// CacheEntry holds what is known about a solved board
+type CacheEntry struct {
+	// optimal number of moves to the goal
+	Distance int
+
+	// first move of an optimal solution, unused for the goal itself
+	Move board.Direction
+}
+
+// cacheItem is the element stored in the recency list
+type cacheItem struct {
+	state board.Board
+	entry CacheEntry
+}
+
+// Cache represents a bounded least recently used cache of solved boards
+// It is safe to share a cache between solvers running concurrently
+type Cache struct {
+	mutex    sync.Mutex
+	capacity int
+
+	// most recently used boards are kept at the front
+	order   *list.List
+	entries map[board.Board]*list.Element
+}
+
+// NewCache returns pointer to a Cache instance keeping at most capacity boards
+func NewCache(capacity int) *Cache {
+	if capacity < 1 {
+		capacity = DefaultCacheSize
+	}
+
+	return &Cache{capacity: capacity, order: list.New(), entries: make(map[board.Board]*list.Element)}
+}
+
+// Len returns the number of boards in the cache
+func (c *Cache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.order.Len()
+}
+
+// Get returns the cached entry for a board
+func (c *Cache) Get(b board.Board) (CacheEntry, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[b]
+	if !ok {
+		return CacheEntry{}, false
+	}
+
+	c.order.MoveToFront(element)
+	return element.Value.(*cacheItem).entry, true
+}
+
+// Put adds or refreshes the entry of a board, evicting the least recent one if full
+func (c *Cache) Put(b board.Board, entry CacheEntry) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.put(b, entry)
+}
+
+// put does the work for Put and Load, mutex must be held
+func (c *Cache) put(b board.Board, entry CacheEntry) {
+	if element, ok := c.entries[b]; ok {
+		element.Value.(*cacheItem).entry = entry
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[b] = c.order.PushFront(&cacheItem{state: b, entry: entry})
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheItem).state)
+	}
+}
+
+// Store records the start and every board on the path of a solved solver
+func (c *Cache) Store(s *Solver) {
+	if !s.Solved {
+		return
+	}
+
+	state, distance := s.Start, s.Path.Len()
+	for e := s.Path.Front(); e != nil; e = e.Next() {
+		next := e.Value.(board.Board)
+
+		move, _ := board.Towards(state, next)
+		c.Put(state, CacheEntry{Distance: distance, Move: move})
+
+		state = next
+		distance--
+	}
+
+	c.Put(state, CacheEntry{Distance: 0})
+}
+
+// Path follows the cached moves from a board to the goal
+// in the same form as Solver.Path, second value is false when a link is missing
+func (c *Cache) Path(b board.Board) (*list.List, bool) {
+	path := list.New()
+
+	entry, ok := c.Get(b)
+	for ok && entry.Distance > 0 {
+		if !b.Slide(entry.Move) {
+			return nil, false
+		}
+		path.PushBack(b)
+
+		var next CacheEntry
+		next, ok = c.Get(b)
+		if ok && next.Distance != entry.Distance-1 {
+			return nil, false
+		}
+
+		entry = next
+	}
+
+	return path, ok
+}
+
+// Save writes the cache to a file, one board per line
+// as distance, move and the board in its text format
+//
+// The lines go to a temporary file first, which then replaces the file,
+// so an interrupted save leaves the previous cache intact.
+func (c *Cache) Save(path string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	file, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	for e := c.order.Front(); e != nil; e = e.Next() {
+		item := e.Value.(*cacheItem)
+		fmt.Fprintf(writer, "%v %v %v\n", item.entry.Distance, item.entry.Move, item.state)
+	}
+
+	if err = writer.Flush(); err == nil {
+		err = file.Chmod(0644)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+	}
+
+	return err
+}
+
+// Load reads a file written by Save into the cache
+func (c *Cache) Load(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var items []cacheItem
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 3 {
+			return fmt.Errorf("solver: %v:%v: malformed cache entry", path, line)
+		}
+
+		distance, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return fmt.Errorf("solver: %v:%v: %v", path, line, err)
+		}
+
+		var move board.Direction
+		if distance > 0 {
+			move, err = board.ParseDirection([]rune(fields[1])[0])
+			if err != nil {
+				return fmt.Errorf("solver: %v:%v: %v", path, line, err)
+			}
+		}
+
+		b, err := board.Parse(strings.Join(fields[2:], " "))
+		if err != nil {
+			return fmt.Errorf("solver: %v:%v: %v", path, line, err)
+		}
+
+		items = append(items, cacheItem{state: *b, entry: CacheEntry{Distance: distance, Move: move}})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// the file lists most recent boards first
+	for i := len(items) - 1; i >= 0; i-- {
+		c.put(items[i].state, items[i].entry)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package solver
+
+import (
+	"github.com/pravj/puzzl/board"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCacheStoresOptimalOnly(t *testing.T) {
+	greedy := HeuristicFunc(func(g board.Grid) int {
+		return 3 * Manhattan{}.Estimate(g)
+	})
+
+	tests := []struct {
+		name   string
+		h      Heuristic
+		stored bool
+	}{
+		{"manhattan", Manhattan{}, true},
+		{"max", Max(Manhattan{}, MisplacedTiles{}), true},
+		{"greedy", greedy, false},
+		{"max with greedy", Max(Manhattan{}, greedy), false},
+	}
+
+	for _, test := range tests {
+		s := NewWithHeuristic(mustParse(t, "8 6 7 2 5 4 3 0 1"), test.h)
+		s.Cache = NewCache(DefaultCacheSize)
+		s.Solve()
+
+		if stored := s.Cache.Len() > 0; stored != test.stored {
+			t.Errorf("%v: cache stored the solution %v, want %v", test.name, stored, test.stored)
+		}
+	}
+}
+
+func TestCacheSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "puzzl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := NewWithHeuristic(mustParse(t, "8 6 7 2 5 4 3 0 1"), Manhattan{})
+	s.Cache = NewCache(DefaultCacheSize)
+	s.Solve()
+
+	path := filepath.Join(dir, "cache")
+	if err := ioutil.WriteFile(path, []byte("previous"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Cache.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// the saved file replaced the previous one, no temporary file is left
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("Save left %v files, want 1", len(files))
+	}
+
+	loaded := NewCache(DefaultCacheSize)
+	if err := loaded.Load(path); err != nil {
+		t.Fatal(err)
+	}
+
+	solution, ok := loaded.Path(s.Start)
+	if !ok {
+		t.Fatal("loaded cache: no path for the start")
+	}
+	if solution.Len() != s.Moves {
+		t.Errorf("loaded cache: %v moves for the start, want %v", solution.Len(), s.Moves)
+	}
+}
@@ -0,0 +1,192 @@
+package solver
+
+import (
+	"fmt"
+	"github.com/pravj/puzzl/board"
+	"math"
+	"math/rand"
+)
+
+// MaxLength is the longest optimal solution of a 3x3 board, God's number
+const MaxLength int = 31
+
+// Level represents a difficulty class of boards
+type Level int
+
+// Difficulty levels, the zero value is a board not rated yet
+const (
+	LevelEasy Level = iota + 1
+	LevelMedium
+	LevelHard
+	LevelExpert
+)
+
+// levelNames holds the name of each level, starting with the unrated one
+var levelNames = [...]string{"", "easy", "medium", "hard", "expert"}
+
+// String returns the name of a level
+func (l Level) String() string {
+	if l < 0 || int(l) >= len(levelNames) {
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+
+	return levelNames[l]
+}
+
+// ParseLevel returns the level for its name
+func ParseLevel(name string) (Level, error) {
+	for i, n := range levelNames {
+		if n == name && i > 0 {
+			return Level(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("solver: unknown difficulty %q, available: %v", name, levelNames[1:])
+}
+
+// Difficulty describes how hard a board is to solve by hand
+//
+// Boards with the same optimal length differ a lot, one having a single
+// narrow solution looks nothing like one where most moves are right.
+type Difficulty struct {
+	// number of moves of an optimal solution
+	Length int
+
+	// number of distinct optimal solutions
+	Solutions int
+
+	// average number of optimal moves at every step of an optimal solution
+	Branching float64
+
+	// solutions two moves longer than optimal, one wrong move and its undoing
+	NearOptimal int
+
+	// moves the heuristic misses at the start, a board looking closer than it is
+	HeuristicError int
+
+	// Score runs from 0 for the goal to 100 for the hardest boards
+	Score float64
+	Level Level
+}
+
+// weights of each part of the score, adding up to 1
+const (
+	lengthWeight    float64 = 0.6
+	forcedWeight    float64 = 0.15
+	detourWeight    float64 = 0.1
+	deceptiveWeight float64 = 0.15
+)
+
+// Rate measures the difficulty of a board against the goal of o,
+// the heuristic error is measured with h
+func Rate(o *Optimal, b board.Board, h Heuristic) Difficulty {
+	length, ok := o.Distance(b)
+	if !ok {
+		return Difficulty{Length: -1}
+	}
+
+	d := Difficulty{Length: length, Solutions: o.Count(b), NearOptimal: nearOptimal(o, b, make(map[board.Board]int))}
+	if length == 0 {
+		d.Level = LevelEasy
+		return d
+	}
+
+	d.Branching = math.Pow(float64(d.Solutions), 1/float64(length))
+	d.HeuristicError = length - h.Estimate(b.Grid())
+
+	// a single line of optimal moves is the hardest to find
+	forced := 1 / (1 + math.Log2(float64(d.Solutions)))
+
+	// near-optimal solutions per optimal one and step, tempting side roads
+	detours := float64(d.NearOptimal) / float64(d.Solutions) / float64(length+1) / 3
+
+	deceptive := float64(d.HeuristicError) / float64(length)
+
+	d.Score = 100 * (lengthWeight*math.Min(1, float64(length)/float64(MaxLength)) +
+		forcedWeight*forced + detourWeight*math.Min(1, detours) + deceptiveWeight*math.Min(1, deceptive))
+
+	// thresholds split random boards roughly 10, 40, 30 and 20 percent
+	switch {
+	case d.Score < 56:
+		d.Level = LevelEasy
+	case d.Score < 65:
+		d.Level = LevelMedium
+	case d.Score < 70:
+		d.Level = LevelHard
+	default:
+		d.Level = LevelExpert
+	}
+
+	return d
+}
+
+// nearOptimal counts the solutions exactly two moves longer than optimal
+//
+// Every move changes the distance by one, so such a solution either starts
+// with an optimal move or with a wrong one followed by an optimal solution.
+func nearOptimal(o *Optimal, b board.Board, memo map[board.Board]int) int {
+	if c, found := memo[b]; found {
+		return c
+	}
+
+	d, _ := o.Distance(b)
+
+	var c int
+	for _, n := range neighbours(b) {
+		next, _ := o.Distance(n)
+		if next < d {
+			c += nearOptimal(o, n, memo)
+		} else {
+			c += o.Count(n)
+		}
+	}
+	memo[b] = c
+
+	return c
+}
+
+// Generate returns a random board of the given level, rated against the goal of o
+// After tries boards without a match the closest one found is returned.
+//
+// Every other board is scrambled from the goal by a random walk,
+// as uniformly random boards are seldom easy ones.
+func Generate(level Level, o *Optimal, h Heuristic, tries int) (board.Board, Difficulty) {
+	var best board.Board
+	var bestRating Difficulty
+	bestGap := -1
+
+	for i := 0; i < tries || bestGap < 0; i++ {
+		b := *board.New()
+		if i%2 == 1 {
+			b = scramble(rand.Intn(2 * MaxLength))
+		}
+		rating := Rate(o, b, h)
+
+		gap := int(level - rating.Level)
+		if gap < 0 {
+			gap = -gap
+		}
+
+		if bestGap < 0 || gap < bestGap {
+			best, bestRating, bestGap = b, rating, gap
+		}
+		if gap == 0 {
+			break
+		}
+	}
+
+	return best, bestRating
+}
+
+// scramble returns the goal board after a random walk of the blank tile
+func scramble(moves int) board.Board {
+	b := *board.Goal()
+
+	for i := 0; i < moves; {
+		if b.Slide(board.Direction(rand.Intn(4))) {
+			i++
+		}
+	}
+
+	return b
+}
@@ -0,0 +1,131 @@
+package solver
+
+import (
+	"fmt"
+	"github.com/pravj/puzzl/board"
+	"sort"
+	"strings"
+)
+
+// Heuristic estimates the number of moves left to reach the goal configuration
+//
+// The solver finds optimal solutions only for an admissible heuristic,
+// one that never overestimates, CheckHeuristic verifies that on small boards.
+// The solver reuses the grid for the next estimate, it must not be kept.
+type Heuristic interface {
+	Estimate(g board.Grid) int
+}
+
+// HeuristicFunc adapts a plain function to the Heuristic interface
+type HeuristicFunc func(g board.Grid) int
+
+// Estimate calls the function itself
+func (f HeuristicFunc) Estimate(g board.Grid) int {
+	return f(g)
+}
+
+// MisplacedTiles counts the tiles which are away from their goal position
+type MisplacedTiles struct{}
+
+// Estimate implements the misplaced tile count, the blank tile is not counted
+func (MisplacedTiles) Estimate(g board.Grid) int {
+	var score int
+
+	for i, v := range g.Tiles {
+		if v != 0 && g.Target(v) != i {
+			score++
+		}
+	}
+
+	return score
+}
+
+// Manhattan sums the row and column distances of tiles from their goal position
+type Manhattan struct{}
+
+// Estimate implements the Manhattan distance, the blank tile is not counted
+func (Manhattan) Estimate(g board.Grid) int {
+	var score int
+
+	for i, v := range g.Tiles {
+		if v == 0 {
+			continue
+		}
+
+		target := g.Target(v)
+		score += abs(i/g.Cols-target/g.Cols) + abs(i%g.Cols-target%g.Cols)
+	}
+
+	return score
+}
+
+// admissible returns whether a heuristic is known to never overestimate,
+// so that the solutions found with it are optimal
+func admissible(h Heuristic) bool {
+	switch h := h.(type) {
+	case MisplacedTiles, Manhattan, WalkingDistance:
+		return true
+	case maxHeuristic:
+		for _, each := range h {
+			if !admissible(each) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// CheckShape returns an error when a heuristic can not estimate boards of a shape
+func CheckShape(h Heuristic, rows, cols int) error {
+	switch h := h.(type) {
+	case WalkingDistance:
+		return h.Supports(rows, cols)
+	case maxHeuristic:
+		for _, each := range h {
+			if err := CheckShape(each, rows, cols); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Heuristics lists the available heuristics by name
+var Heuristics = map[string]Heuristic{
+	"misplaced": MisplacedTiles{},
+	"manhattan": Manhattan{},
+	"walking":   WalkingDistance{},
+}
+
+// LookupHeuristic returns the heuristic for a name from Heuristics,
+// several comma separated names are combined with Max
+func LookupHeuristic(names string) (Heuristic, error) {
+	var hs []Heuristic
+
+	for _, name := range strings.Split(names, ",") {
+		h, ok := Heuristics[strings.TrimSpace(name)]
+		if !ok {
+			var available []string
+			for n := range Heuristics {
+				available = append(available, n)
+			}
+			sort.Strings(available)
+
+			return nil, fmt.Errorf("solver: unknown heuristic %q, available: %v", name, strings.Join(available, ", "))
+		}
+
+		hs = append(hs, h)
+	}
+
+	if len(hs) == 1 {
+		return hs[0], nil
+	}
+
+	return Max(hs...), nil
+}
+
+// DefaultHeuristic is used by solvers created with New
+var DefaultHeuristic Heuristic = MisplacedTiles{}
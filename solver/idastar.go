@@ -0,0 +1,125 @@
+package solver
+
+import (
+	"github.com/pravj/puzzl/board"
+)
+
+// IDAStar solves a configuration of any shape with iterative deepening A-star
+//
+// It keeps only the current path in memory, which makes boards like the
+// 15 puzzle possible where A-star runs out of memory. Returns the moves of
+// the blank tile, the number of expanded nodes and false if unsolvable.
+func IDAStar(g board.Grid, h Heuristic) ([]board.Direction, int, bool) {
+	if !g.Solvable() {
+		return nil, 0, false
+	}
+
+	tiles := make([]int, len(g.Tiles))
+	copy(tiles, g.Tiles)
+
+	search := &idaSearch{grid: board.Grid{Rows: g.Rows, Cols: g.Cols, Tiles: tiles}, heuristic: h}
+	search.blank = search.grid.Blank()
+
+	bound := h.Estimate(search.grid)
+	for {
+		next := search.deepen(0, bound, -1)
+		if next < 0 {
+			return search.path, search.expanded, true
+		}
+
+		bound = next
+	}
+}
+
+// idaSearch holds the state of a depth first search iteration
+type idaSearch struct {
+	grid      board.Grid
+	blank     int
+	heuristic Heuristic
+
+	path     []board.Direction
+	expanded int
+}
+
+// opposite holds the direction undoing each direction
+var opposite = [...]board.Direction{board.Down, board.Up, board.Right, board.Left}
+
+// deepen searches below the current configuration within the cost bound
+// returns -1 once the goal is found, otherwise the smallest cost beyond the bound
+func (s *idaSearch) deepen(g, bound int, last board.Direction) int {
+	f := g + s.heuristic.Estimate(s.grid)
+	if f > bound {
+		return f
+	}
+
+	if s.isGoal() {
+		return -1
+	}
+
+	s.expanded++
+	least := int(^uint(0) >> 1)
+
+	row, col := s.blank/s.grid.Cols, s.blank%s.grid.Cols
+	for d := board.Up; d <= board.Right; d++ {
+		// going straight back never helps
+		if last >= 0 && d == opposite[last] {
+			continue
+		}
+
+		target := s.blank
+		switch d {
+		case board.Up:
+			if row == 0 {
+				continue
+			}
+			target -= s.grid.Cols
+		case board.Down:
+			if row == s.grid.Rows-1 {
+				continue
+			}
+			target += s.grid.Cols
+		case board.Left:
+			if col == 0 {
+				continue
+			}
+			target--
+		case board.Right:
+			if col == s.grid.Cols-1 {
+				continue
+			}
+			target++
+		}
+
+		blank := s.blank
+		s.grid.Tiles[blank], s.grid.Tiles[target] = s.grid.Tiles[target], 0
+		s.blank = target
+		s.path = append(s.path, d)
+
+		next := s.deepen(g+1, bound, d)
+		if next < 0 {
+			return -1
+		}
+
+		s.path = s.path[:len(s.path)-1]
+		s.blank = blank
+		s.grid.Tiles[target], s.grid.Tiles[blank] = s.grid.Tiles[blank], 0
+
+		if next < least {
+			least = next
+		}
+	}
+
+	return least
+}
+
+// isGoal returns whether the tiles are in the goal configuration
+func (s *idaSearch) isGoal() bool {
+	n := len(s.grid.Tiles)
+	for i, v := range s.grid.Tiles {
+		if v != (i+1)%n {
+			return false
+		}
+	}
+
+	return true
+}
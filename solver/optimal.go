@@ -0,0 +1,172 @@
+package solver
+
+import (
+	"container/list"
+	"github.com/pravj/puzzl/board"
+)
+
+// Optimal keeps exact goal distances of board configurations
+// It helps reasoning about every optimal solution instead of a single one
+type Optimal struct {
+	distance map[board.Board]int
+	counts   map[board.Board]int
+
+	// breadth first search layer that is yet to be expanded
+	frontier []board.Board
+	depth    int
+}
+
+// NewOptimal returns pointer to an Optimal instance for the given goal states
+//
+// Distances are discovered lazily with a breadth first search from the goals,
+// which grows only as far as the deepest configuration asked for.
+func NewOptimal(goals ...board.Board) *Optimal {
+	o := &Optimal{distance: make(map[board.Board]int), counts: make(map[board.Board]int)}
+
+	for _, g := range goals {
+		if _, ok := o.distance[g]; !ok {
+			o.distance[g] = 0
+			o.frontier = append(o.frontier, g)
+		}
+	}
+
+	return o
+}
+
+// explore expands the search by one more layer
+// returns false when the whole state space has already been covered
+func (o *Optimal) explore() bool {
+	if len(o.frontier) == 0 {
+		return false
+	}
+
+	var next []board.Board
+	for _, b := range o.frontier {
+		for _, n := range neighbours(b) {
+			if _, ok := o.distance[n]; !ok {
+				o.distance[n] = o.depth + 1
+				next = append(next, n)
+			}
+		}
+	}
+
+	o.frontier = next
+	o.depth++
+
+	return true
+}
+
+// Distance returns the optimal number of moves from a board to the goal
+// second value is false when the goal can not be reached from the board
+func (o *Optimal) Distance(b board.Board) (int, bool) {
+	for {
+		if d, ok := o.distance[b]; ok {
+			return d, true
+		}
+
+		if !o.explore() {
+			return -1, false
+		}
+	}
+}
+
+// IsOptimalMove returns whether moving from one board to another
+// keeps the player on any of the optimal solutions
+func (o *Optimal) IsOptimalMove(from, to board.Board) bool {
+	d, ok := o.Distance(from)
+	if !ok || d == 0 {
+		return false
+	}
+
+	next, _ := o.Distance(to)
+	return next == d-1
+}
+
+// FirstMoves returns all the adjacent configurations
+// which lie on an optimal solution from the given board
+func (o *Optimal) FirstMoves(b board.Board) []board.Board {
+	var moves []board.Board
+
+	d, ok := o.Distance(b)
+	if !ok || d == 0 {
+		return moves
+	}
+
+	// every neighbour at distance d-1 has already been discovered
+	for _, n := range neighbours(b) {
+		if next, found := o.distance[n]; found && next == d-1 {
+			moves = append(moves, n)
+		}
+	}
+
+	return moves
+}
+
+// Count returns the number of distinct optimal solutions from a board
+func (o *Optimal) Count(b board.Board) int {
+	d, ok := o.Distance(b)
+	if !ok {
+		return 0
+	}
+	if d == 0 {
+		return 1
+	}
+
+	if c, found := o.counts[b]; found {
+		return c
+	}
+
+	var c int
+	for _, n := range o.FirstMoves(b) {
+		c += o.Count(n)
+	}
+	o.counts[b] = c
+
+	return c
+}
+
+// Solutions enumerates optimal solutions from a board
+// Every solution lists the configurations after each move, ending with the goal.
+// A limit less than one enumerates all of them.
+func (o *Optimal) Solutions(b board.Board, limit int) [][]board.Board {
+	var solutions [][]board.Board
+
+	if _, ok := o.Distance(b); !ok {
+		return solutions
+	}
+
+	var walk func(state board.Board, path []board.Board)
+	walk = func(state board.Board, path []board.Board) {
+		if limit > 0 && len(solutions) >= limit {
+			return
+		}
+
+		moves := o.FirstMoves(state)
+		if len(moves) == 0 {
+			solution := make([]board.Board, len(path))
+			copy(solution, path)
+			solutions = append(solutions, solution)
+			return
+		}
+
+		for _, n := range moves {
+			walk(n, append(path, n))
+		}
+	}
+	walk(b, nil)
+
+	return solutions
+}
+
+// Path returns one optimal path from a board to the goal
+// in the same form as Solver.Path, the board itself is not included
+func (o *Optimal) Path(b board.Board) *list.List {
+	path := list.New()
+
+	for moves := o.FirstMoves(b); len(moves) > 0; moves = o.FirstMoves(b) {
+		b = moves[0]
+		path.PushBack(b)
+	}
+
+	return path
+}
@@ -0,0 +1,82 @@
+package solver
+
+import (
+	"github.com/pravj/puzzl/board"
+	"testing"
+)
+
+// countPaths returns the number of move sequences of a length leading from a board to the goal
+func countPaths(b board.Board, moves int) int {
+	if moves == 0 {
+		if b == *board.Goal() {
+			return 1
+		}
+		return 0
+	}
+
+	var c int
+	for d := board.Up; d <= board.Right; d++ {
+		next := b
+		if next.Slide(d) {
+			c += countPaths(next, moves-1)
+		}
+	}
+
+	return c
+}
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		start    string
+		distance int
+		count    int
+	}{
+		{"1 2 3 4 5 6 7 8 0", 0, 1},
+		{"1 2 3 4 5 6 0 7 8", 2, 1},
+		{"4 1 3 7 2 6 5 8 0", 8, 1},
+		{"1 2 3 4 0 8 7 6 5", 6, 2},
+		{"4 0 3 2 1 8 7 6 5", 11, 4},
+	}
+
+	o := NewOptimal(*board.Goal())
+
+	for _, test := range tests {
+		b := mustParse(t, test.start)
+
+		if d, _ := o.Distance(*b); d != test.distance {
+			t.Errorf("%v: distance %v, want %v", test.start, d, test.distance)
+			continue
+		}
+
+		// every sequence of that many moves reaching the goal is an optimal solution
+		count := o.Count(*b)
+		if want := countPaths(*b, test.distance); count != want || count != test.count {
+			t.Errorf("%v: %v optimal solutions, want %v (%v found by trying every move)", test.start, count, test.count, want)
+		}
+
+		solutions := o.Solutions(*b, 0)
+		if len(solutions) != count {
+			t.Errorf("%v: %v solutions enumerated, want %v", test.start, len(solutions), count)
+		}
+
+		seen := make(map[string]bool)
+		for _, solution := range solutions {
+			from, key := *b, ""
+			for _, next := range solution {
+				if !isNeighbour(from, next) {
+					t.Errorf("%v: solution %v makes an impossible move", test.start, solution)
+					break
+				}
+				from, key = next, key+next.String()+"|"
+			}
+			if len(solution) != test.distance || from != *board.Goal() || seen[key] {
+				t.Errorf("%v: solution %v is not a distinct optimal one", test.start, solution)
+			}
+			seen[key] = true
+		}
+
+		if limited := o.Solutions(*b, 1); len(limited) != 1 {
+			t.Errorf("%v: %v solutions with a limit of one", test.start, len(limited))
+		}
+	}
+}
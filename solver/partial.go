@@ -0,0 +1,164 @@
+package solver
+
+import (
+	"fmt"
+	"github.com/pravj/puzzl/board"
+	"github.com/pravj/puzzl/scanner"
+)
+
+// PartialGoal represents a goal where only the chosen tiles
+// (and optionally the blank tile) have to reach their goal positions
+type PartialGoal struct {
+	Tiles []int
+	Blank bool
+}
+
+// TeachingStagesFor returns stages placing the top row first and then finishing
+// the rest of the board, partial goals are only for boards of board.SIZE though
+func TeachingStagesFor(rows, cols int) ([]PartialGoal, error) {
+	if rows != board.SIZE || cols != board.SIZE {
+		return nil, fmt.Errorf("solver: teaching stages are for %vx%v boards, not %vx%v", board.SIZE, board.SIZE, rows, cols)
+	}
+
+	var top, all []int
+	for value := 1; value < rows*cols; value++ {
+		if value <= cols {
+			top = append(top, value)
+		}
+		all = append(all, value)
+	}
+
+	return []PartialGoal{{Tiles: top}, {Tiles: all, Blank: true}}, nil
+}
+
+// goalPosition returns zero-based row and column of a tile value in the goal state
+func goalPosition(value int) (int, int) {
+	if value == 0 {
+		return board.SIZE - 1, board.SIZE - 1
+	}
+
+	return (value - 1) / board.SIZE, (value - 1) % board.SIZE
+}
+
+// abs returns the absolute value of an integer
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
+
+// selected returns whether a tile value is part of the goal
+func (g PartialGoal) selected(value int) bool {
+	if value == 0 {
+		return g.Blank
+	}
+
+	for _, t := range g.Tiles {
+		if t == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reached returns whether all the chosen tiles are at their goal positions
+func (g PartialGoal) Reached(b board.Board) bool {
+	for i := 0; i < board.SIZE; i++ {
+		for j := 0; j < board.SIZE; j++ {
+			value := b.Rows[i].Tiles[j].Value
+			if !g.selected(value) {
+				continue
+			}
+
+			if row, col := goalPosition(value); row != i || col != j {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Estimate implements the Manhattan distance restricted to the chosen tiles
+//
+// It never overestimates, every chosen tile needs at least that many moves.
+// The blank tile is kept out of the sum, as it moves along with every tile,
+// but its own distance is still a lower bound when it is larger.
+func (g PartialGoal) Estimate(b board.Board) int {
+	var tiles, blank int
+
+	for i := 0; i < board.SIZE; i++ {
+		for j := 0; j < board.SIZE; j++ {
+			value := b.Rows[i].Tiles[j].Value
+			if !g.selected(value) {
+				continue
+			}
+
+			row, col := goalPosition(value)
+			distance := abs(row-i) + abs(col-j)
+
+			if value == 0 {
+				blank = distance
+			} else {
+				tiles += distance
+			}
+		}
+	}
+
+	if blank > tiles {
+		return blank
+	}
+
+	return tiles
+}
+
+// States returns all the configurations reachable from a board
+// in which the partial goal is reached
+func (g PartialGoal) States(b board.Board) []board.Board {
+	var states []board.Board
+
+	// cells and values which the goal leaves free
+	var cells, values []int
+	for index := 0; index < board.SIZE*board.SIZE; index++ {
+		value := (index + 1) % (board.SIZE * board.SIZE)
+		if !g.selected(value) {
+			cells = append(cells, index)
+			values = append(values, value)
+		}
+	}
+
+	tiles := make([]int, board.SIZE*board.SIZE)
+	for index := range tiles {
+		tiles[index] = (index + 1) % (board.SIZE * board.SIZE)
+	}
+
+	var place func(k int)
+	place = func(k int) {
+		if k == len(cells) {
+			// only the configurations of the same class as the board are reachable
+			if legal, blank := scanner.IsLegal(board.SIZE, tiles); legal {
+				state := b
+				for index, value := range tiles {
+					state.Rows[index/board.SIZE].Tiles[index%board.SIZE].Value = value
+				}
+				state.BlankRow, state.BlankCol = blank/board.SIZE, blank%board.SIZE
+
+				states = append(states, state)
+			}
+			return
+		}
+
+		for i := k; i < len(values); i++ {
+			values[k], values[i] = values[i], values[k]
+			tiles[cells[k]] = values[k]
+			place(k + 1)
+			values[k], values[i] = values[i], values[k]
+		}
+	}
+	place(0)
+
+	return states
+}
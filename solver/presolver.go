@@ -0,0 +1,130 @@
+package solver
+
+import (
+	"github.com/pravj/puzzl/board"
+	"runtime"
+	"sync"
+)
+
+// PresolveDepth is the number of moves around the current board
+// for which configurations are solved ahead of time
+const PresolveDepth int = 2
+
+// Presolver speculatively solves the configurations near the current board
+// on background goroutines, so the plan for the next board is ready in advance
+type Presolver struct {
+	mutex   sync.Mutex
+	solvers map[board.Board]*Solver
+
+	// searches still running, closing the channel cancels one
+	pending map[board.Board]chan struct{}
+
+	// limits the number of searches running at the same time
+	slots chan bool
+
+	// optional cache shared by the background solvers
+	Cache *Cache
+
+	// settings of the background solvers, set before the first Prefetch
+	Heuristic Heuristic
+	TieBreak  TieBreak
+	Queue     QueueKind
+}
+
+// NewPresolver returns pointer to a new Presolver instance
+func NewPresolver() *Presolver {
+	return &Presolver{
+		solvers:   make(map[board.Board]*Solver),
+		pending:   make(map[board.Board]chan struct{}),
+		slots:     make(chan bool, runtime.NumCPU()),
+		Heuristic: DefaultHeuristic,
+	}
+}
+
+// Prefetch starts solving every board reachable from the given one
+// in at most PresolveDepth moves, results and searches away from it are dropped
+func (p *Presolver) Prefetch(b board.Board) {
+	nearby := map[board.Board]bool{b: true}
+	layer := []board.Board{b}
+
+	for depth := 0; depth < PresolveDepth; depth++ {
+		var next []board.Board
+		for _, state := range layer {
+			for _, n := range neighbours(state) {
+				if !nearby[n] {
+					nearby[n] = true
+					next = append(next, n)
+				}
+			}
+		}
+		layer = next
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for state := range p.solvers {
+		if !nearby[state] {
+			delete(p.solvers, state)
+		}
+	}
+
+	for state, cancel := range p.pending {
+		if !nearby[state] {
+			close(cancel)
+			delete(p.pending, state)
+		}
+	}
+
+	for state := range nearby {
+		if p.solvers[state] != nil || p.pending[state] != nil {
+			continue
+		}
+
+		cancel := make(chan struct{})
+		p.pending[state] = cancel
+		go p.solve(state, cancel)
+	}
+}
+
+// solve runs the search for a board and stores the solved instance,
+// unless the search is cancelled before or while it runs
+func (p *Presolver) solve(b board.Board, cancel chan struct{}) {
+	select {
+	case p.slots <- true:
+	case <-cancel:
+		return
+	}
+
+	s := NewWithHeuristic(&b, p.Heuristic)
+	s.Cache = p.Cache
+	s.TieBreak = p.TieBreak
+	s.Queue = p.Queue
+	s.Cancel = cancel
+	s.Solve()
+	<-p.slots
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// a cancelled search may have been started again since
+	if p.pending[b] == cancel {
+		delete(p.pending, b)
+	}
+	if s.Solved {
+		p.solvers[b] = s
+	}
+}
+
+// Lookup returns a copy of the solved instance for a board, if it is already available
+func (p *Presolver) Lookup(b board.Board) (*Solver, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	s, ok := p.solvers[b]
+	if !ok {
+		return nil, false
+	}
+
+	return s.Follow(&b, s.Path), true
+}
@@ -0,0 +1,188 @@
+package solver
+
+import (
+	"container/heap"
+)
+
+// QueueKind represents the data structure used for the open list
+type QueueKind int
+
+// Open list implementations
+const (
+	// QueueAuto uses buckets when the heuristic has bounded integer estimates
+	// (every heuristic of this package does) and the binary heap otherwise
+	QueueAuto QueueKind = iota
+
+	// QueueHeap uses the binary heap PriorityQueue
+	QueueHeap
+
+	// QueueBuckets uses the BucketQueue
+	QueueBuckets
+)
+
+// nodeQueue is the ordering of nodes in the open list
+type nodeQueue interface {
+	Len() int
+	push(n *Node)
+	pop() *Node
+}
+
+// newQueue returns the open list implementation chosen for the solver
+func (s *Solver) newQueue() nodeQueue {
+	kind := s.Queue
+	if kind == QueueAuto {
+		kind = QueueHeap
+		if s.partial != nil || bounded(s.heuristic) {
+			kind = QueueBuckets
+		}
+	}
+
+	if kind == QueueBuckets {
+		return NewBucketQueue(s.TieBreak)
+	}
+
+	pq := &PriorityQueue{policy: s.TieBreak}
+	heap.Init(pq)
+
+	return heapQueue{pq}
+}
+
+// bounded returns whether a heuristic is known to give small non-negative estimates
+func bounded(h Heuristic) bool {
+	switch h := h.(type) {
+	case MisplacedTiles, Manhattan, WalkingDistance:
+		return true
+	case maxHeuristic:
+		for _, each := range h {
+			if !bounded(each) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// heapQueue adapts the PriorityQueue to the open list ordering
+type heapQueue struct {
+	pq *PriorityQueue
+}
+
+// Len returns the number of queued nodes
+func (q heapQueue) Len() int {
+	return q.pq.Len()
+}
+
+// push adds a node to the heap
+func (q heapQueue) push(n *Node) {
+	heap.Push(q.pq, n)
+}
+
+// pop removes the most prioritized node from the heap
+func (q heapQueue) pop() *Node {
+	return heap.Pop(q.pq).(*Node)
+}
+
+// bucket is a list of nodes sharing the same costs
+type bucket struct {
+	nodes []*Node
+
+	// index of the first node still queued, for removal in insertion order
+	head int
+}
+
+// BucketQueue represents a priority queue for integer f-costs
+//
+// Nodes are kept in one bucket per f-cost, split further by g-cost
+// for the tie-breaking policies that look at it, so pushing and popping
+// take constant time and nodes are never moved around once queued.
+type BucketQueue struct {
+	levels [][]bucket
+	policy TieBreak
+
+	count int
+
+	// lowest f-cost which may have a queued node
+	low int
+}
+
+// NewBucketQueue returns pointer to a BucketQueue instance using the tie-breaking policy
+func NewBucketQueue(policy TieBreak) *BucketQueue {
+	return &BucketQueue{policy: policy}
+}
+
+// Len returns the number of queued nodes
+func (q *BucketQueue) Len() int {
+	return q.count
+}
+
+// byCost returns whether the policy orders equal f-cost nodes by their g-cost
+func (q *BucketQueue) byCost() bool {
+	return q.policy == TieHighG || q.policy == TieLowH
+}
+
+// push adds a node to the bucket of its costs
+func (q *BucketQueue) push(n *Node) {
+	f, g := n.fCost, 0
+	if q.byCost() {
+		g = n.gCost
+	}
+
+	for len(q.levels) <= f {
+		q.levels = append(q.levels, nil)
+	}
+	for len(q.levels[f]) <= g {
+		q.levels[f] = append(q.levels[f], bucket{})
+	}
+
+	b := &q.levels[f][g]
+	b.nodes = append(b.nodes, n)
+
+	q.count++
+	if f < q.low {
+		q.low = f
+	}
+}
+
+// pop removes the most prioritized node, nil when the queue is empty
+//
+// Equal g-cost nodes come out in insertion order, except for TieLIFO,
+// just like the PriorityQueue does with the same policy.
+func (q *BucketQueue) pop() *Node {
+	if q.count == 0 {
+		return nil
+	}
+
+	for ; q.low < len(q.levels); q.low++ {
+		level := q.levels[q.low]
+
+		// the highest g-cost comes first, same f-cost means lowest h-cost too
+		for g := len(level) - 1; g >= 0; g-- {
+			b := &level[g]
+			if b.head == len(b.nodes) {
+				continue
+			}
+
+			var n *Node
+			if q.policy == TieLIFO {
+				n = b.nodes[len(b.nodes)-1]
+				b.nodes = b.nodes[:len(b.nodes)-1]
+			} else {
+				n = b.nodes[b.head]
+				b.nodes[b.head] = nil
+				b.head++
+			}
+
+			// reuses the bucket's storage once it is drained
+			if b.head == len(b.nodes) {
+				b.nodes, b.head = b.nodes[:0], 0
+			}
+
+			q.count--
+			return n
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package solver
+
+import (
+	"github.com/pravj/puzzl/board"
+	"testing"
+)
+
+// queueBoards are the boards solved by the queue benchmarks, the hardest 8-puzzle among them
+var queueBoards = []string{
+	"8 6 7 2 5 4 3 0 1",
+	"6 4 7 8 5 0 3 2 1",
+	"0 8 7 6 5 4 3 2 1",
+	"1 2 3 4 5 6 7 8 0",
+}
+
+// benchmarkQueue solves the queue boards with an open list implementation
+func benchmarkQueue(b *testing.B, kind QueueKind, h Heuristic) {
+	var boards []*board.Board
+	for _, text := range queueBoards {
+		boards = append(boards, mustParse(b, text))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, start := range boards {
+			s := NewWithHeuristic(start, h)
+			s.Queue = kind
+			s.Solve()
+		}
+	}
+}
+
+func BenchmarkHeapManhattan(b *testing.B) {
+	benchmarkQueue(b, QueueHeap, Manhattan{})
+}
+
+func BenchmarkBucketsManhattan(b *testing.B) {
+	benchmarkQueue(b, QueueBuckets, Manhattan{})
+}
+
+func BenchmarkHeapMisplaced(b *testing.B) {
+	benchmarkQueue(b, QueueHeap, MisplacedTiles{})
+}
+
+func BenchmarkBucketsMisplaced(b *testing.B) {
+	benchmarkQueue(b, QueueBuckets, MisplacedTiles{})
+}
+
+// TestQueuesAgree checks that both open lists expand the same nodes in the same
+// order for every tie-breaking policy, TieNone leaves the order to the queue
+// so only the solution lengths have to agree then
+func TestQueuesAgree(t *testing.T) {
+	for _, text := range queueBoards {
+		for tie := TieHighG; tie <= TieNone; tie++ {
+			var solvers []*Solver
+			for _, kind := range []QueueKind{QueueHeap, QueueBuckets} {
+				s := NewWithHeuristic(mustParse(t, text), Manhattan{})
+				s.Queue, s.TieBreak, s.Tracer = kind, tie, NewTracer(0)
+				s.Solve()
+				solvers = append(solvers, s)
+			}
+
+			heap, buckets := solvers[0], solvers[1]
+			if heap.Moves != buckets.Moves {
+				t.Errorf("%v %v: %v moves with the heap, %v with buckets", text, tie, heap.Moves, buckets.Moves)
+			}
+			if tie == TieNone {
+				continue
+			}
+
+			if heap.Expanded != buckets.Expanded {
+				t.Errorf("%v %v: %v nodes expanded with the heap, %v with buckets", text, tie, heap.Expanded, buckets.Expanded)
+				continue
+			}
+			for i := range heap.Tracer.nodes {
+				if heap.Tracer.nodes[i].state != buckets.Tracer.nodes[i].state {
+					t.Errorf("%v %v: expansion %v differs between the heap and buckets", text, tie, i+1)
+					break
+				}
+			}
+		}
+	}
+}
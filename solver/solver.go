@@ -5,7 +5,6 @@ package solver
 // I smell it using some extra memory and that's not legit my friend.
 
 import (
-	"container/heap"
 	"container/list"
 	"github.com/pravj/puzzl/board"
 )
@@ -20,53 +19,85 @@ type Node struct {
 	fCost int
 
 	index int
+	order int
 }
 
 // PriorityQueue represents a priority queue data structure
 // that contains the game's state space nodes
-type PriorityQueue []Node
+type PriorityQueue struct {
+	nodes []*Node
+
+	// order among nodes having the same f-cost
+	policy TieBreak
+
+	// number of nodes pushed so far, used as insertion order
+	pushed int
+}
 
 // Len returns the length of priority queue
-func (pq PriorityQueue) Len() int {
-	return len(pq)
+func (pq *PriorityQueue) Len() int {
+	return len(pq.nodes)
 }
 
 // Less helps removing the most prioritized node
-func (pq PriorityQueue) Less(i, j int) bool {
-	return pq[i].fCost < pq[j].fCost
+func (pq *PriorityQueue) Less(i, j int) bool {
+	a, b := pq.nodes[i], pq.nodes[j]
+	if a.fCost != b.fCost || pq.policy == TieNone {
+		return a.fCost < b.fCost
+	}
+
+	switch pq.policy {
+	case TieHighG:
+		if a.gCost != b.gCost {
+			return a.gCost > b.gCost
+		}
+	case TieLowH:
+		if a.hCost != b.hCost {
+			return a.hCost < b.hCost
+		}
+	case TieLIFO:
+		return a.order > b.order
+	}
+
+	// remaining ties come out in insertion order
+	return a.order < b.order
 }
 
 // Swap interchanges two game node states with each other
-func (pq PriorityQueue) Swap(i, j int) {
-	pq[i], pq[j] = pq[j], pq[i]
-	pq[i].index = i
-	pq[j].index = j
+func (pq *PriorityQueue) Swap(i, j int) {
+	pq.nodes[i], pq.nodes[j] = pq.nodes[j], pq.nodes[i]
+	pq.nodes[i].index = i
+	pq.nodes[j].index = j
 }
 
 // Push adds a node to the priority queue
 func (pq *PriorityQueue) Push(x interface{}) {
-	n := len(*pq)
-	item := x.(Node)
+	n := len(pq.nodes)
+	item := x.(*Node)
 	item.index = n
-	*pq = append(*pq, item)
+
+	pq.pushed++
+	item.order = pq.pushed
+
+	pq.nodes = append(pq.nodes, item)
 }
 
 // Pop removes a node from the priority queue
 func (pq *PriorityQueue) Pop() interface{} {
-	old := *pq
+	old := pq.nodes
 	n := len(old)
 	item := old[n-1]
 	item.index = -1 // for safety
-	*pq = old[0 : n-1]
+	old[n-1] = nil
+	pq.nodes = old[0 : n-1]
 	return item
 }
 
 // OpenList represents a data-structure used for labeling nodes
 type OpenList struct {
-	nodeTable map[board.Board]Node
-	table     map[board.Board]bool
+	nodeTable map[board.Board]*Node
 
-	queue *PriorityQueue
+	queue nodeQueue
 }
 
 // CloseList represents a data-structure used for labeling nodes
@@ -80,47 +111,98 @@ type Solver struct {
 	openlist  *OpenList
 	closelist *CloseList
 
-	relation map[board.Board]board.Board
-	Path     *list.List
-	Moves    int
+	Path  *list.List
+	Moves int
 
-	Goal board.Board
+	Start board.Board
+	Goal  board.Board
 
 	Solved bool
-}
 
-// implements misplaced tile count as a heuristic scoring function
-func heuristicScore(b board.Board) int {
-	var score int
+	// optional cache of optimally solved boards shared across solvers
+	Cache *Cache
 
-	for i := 0; i < 3; i++ {
-		for j := 0; j < 3; j++ {
-			if b.Rows[i].Tiles[j].Value != ((3*i + j + 1) % 9) {
-				score++
-			}
-		}
-	}
+	// optional goal covering only some of the tiles
+	partial *PartialGoal
+
+	heuristic Heuristic
+
+	// grid the nodes are copied into for the heuristic, instead of a new one per node
+	scratch board.Grid
+
+	// nodes handed out by newNode and the neighbours of the expanded node,
+	// instead of allocating them one by one
+	pool     []Node
+	adjacent []board.Board
+
+	// optional recorder of the expanded nodes
+	Tracer *Tracer
+
+	// order of the nodes having the same f-cost, DefaultTieBreak unless set before solving
+	TieBreak TieBreak
+
+	// open list implementation, set before solving
+	Queue QueueKind
 
-	return score
+	// optional channel whose closing stops the search unsolved
+	Cancel <-chan struct{}
+
+	// number of nodes expanded by the search
+	Expanded int
 }
 
 // scoring updates scores for a Node used in the progress
-func scoring(node *Node, isRoot bool) {
+func (s *Solver) scoring(node *Node, isRoot bool) {
 	var g int
 	if !isRoot {
 		g = node.parent.gCost + 1
 	}
 
-	h := heuristicScore(node.state)
+	var h int
+	if s.partial != nil {
+		h = s.partial.Estimate(node.state)
+	} else {
+		node.state.Fill(&s.scratch)
+		h = s.heuristic.Estimate(s.scratch)
+	}
 	f := g + h
 
 	node.gCost, node.hCost, node.fCost = g, h, f
 }
 
+// reached returns whether a configuration satisfies the solver's goal
+func (s *Solver) reached(b board.Board) bool {
+	if s.partial != nil {
+		return s.partial.Reached(b)
+	}
+
+	return b == s.Goal
+}
+
+// nodeBlock is the number of nodes a solver allocates at once
+const nodeBlock int = 1024
+
+// newNode returns a node for a state reached from parent, taken from the solver's pool
+func (s *Solver) newNode(parent *Node, state board.Board) *Node {
+	if len(s.pool) == 0 {
+		s.pool = make([]Node, nodeBlock)
+	}
+
+	node := &s.pool[0]
+	s.pool = s.pool[1:]
+	node.parent, node.state = parent, state
+
+	return node
+}
+
 // Neighbours returns a list of board configurations
 // adjacent to a given configuration
 func neighbours(b board.Board) []board.Board {
-	var list []board.Board
+	return appendNeighbours(nil, b)
+}
+
+// appendNeighbours appends the configurations adjacent to a given one to list
+func appendNeighbours(list []board.Board, b board.Board) []board.Board {
 	moves := b.Moves(b.BlankRow, b.BlankCol)
 
 	for i := 0; i < len(moves)/2; i++ {
@@ -135,35 +217,42 @@ func neighbours(b board.Board) []board.Board {
 
 // New returns pointer to a Solver instance
 func New(b *board.Board) *Solver {
+	return newSolver(b, nil, DefaultHeuristic)
+}
+
+// NewWithHeuristic returns pointer to a Solver instance using the given heuristic
+func NewWithHeuristic(b *board.Board, h Heuristic) *Solver {
+	return newSolver(b, nil, h)
+}
+
+// NewPartial returns pointer to a Solver instance
+// that stops as soon as the partial goal is reached
+func NewPartial(b *board.Board, goal PartialGoal) *Solver {
+	return newSolver(b, &goal, DefaultHeuristic)
+}
+
+// newSolver does the work for New, NewWithHeuristic and NewPartial
+func newSolver(b *board.Board, partial *PartialGoal, h Heuristic) *Solver {
 	openlist := &OpenList{}
 	closelist := &CloseList{}
 
 	// initiate the solver with open and close lists
-	solver := &Solver{openlist: openlist, closelist: closelist}
+	solver := &Solver{openlist: openlist, closelist: closelist, Start: *b, partial: partial, heuristic: h}
 
 	// initiate traversal lists
-	solver.openlist.nodeTable = make(map[board.Board]Node)
-	solver.openlist.table = make(map[board.Board]bool)
+	solver.openlist.nodeTable = make(map[board.Board]*Node)
 	solver.closelist.table = make(map[board.Board]bool)
 
-	// initiate parent-child relationship and path
-	solver.relation = make(map[board.Board]board.Board)
+	// initiate path
 	solver.Path = list.New()
 
-	var opq PriorityQueue
-	heap.Init(&opq)
-
-	solver.openlist.queue = &opq
-
 	// Node representing the initial configuration of the board
-	currentNode := &Node{parent: nil, state: *b}
+	currentNode := solver.newNode(nil, *b)
 	// updates traversal cost values for the node(root)
-	scoring(currentNode, true)
+	solver.scoring(currentNode, true)
 
-	// add initial configuration(root Node) to open list
-	solver.openlist.nodeTable[currentNode.state] = *currentNode
-	solver.openlist.table[currentNode.state] = true
-	heap.Push(solver.openlist.queue, *currentNode)
+	// add initial configuration(root Node) to open list, queued when solving starts
+	solver.openlist.nodeTable[currentNode.state] = currentNode
 
 	// generate the default goal state for the process
 	solver.goalState()
@@ -172,58 +261,93 @@ func New(b *board.Board) *Solver {
 }
 
 // goalState generates the default goal state
-// TODO: size as a variable(const)
 func (s *Solver) goalState() {
-	b := board.New()
+	s.Goal = *board.Goal()
+}
 
-	for i := 0; i < 3; i++ {
-		for j := 0; j < 3; j++ {
-			b.Rows[i].Tiles[j].Value = (3*i + j + 1) % 9
-		}
+// cancelInterval is the number of expansions between checks of the Cancel channel
+const cancelInterval int = 256
+
+// Solve implements the A-star algorithm to solve a particular tile configuration
+func (s *Solver) Solve() {
+	var currentNode *Node
+
+	// cached distances are only known for the complete goal
+	cache := s.Cache
+	if s.partial != nil {
+		cache = nil
 	}
 
-	b.BlankRow, b.BlankCol = 2, 2
+	// a cached solution for the start needs no search at all
+	if cache != nil {
+		if path, ok := s.Cache.Path(s.Start); ok {
+			s.finish(path)
+			return
+		}
+	}
 
-	s.Goal = *b
-}
+	// best solution known through a cached board, an upper bound for the search
+	bound := -1
+	var boundNode *Node
+	var boundSuffix *list.List
 
-// Solve implements the A-star algorithm to solve a particular tile configuration
-func (s *Solver) Solve() {
-	var currentNode Node
-	var count int
-	var start board.Board
+	s.openlist.queue = s.newQueue()
+	s.openlist.queue.push(s.openlist.nodeTable[s.Start])
 
 	for s.openlist.queue.Len() > 0 {
+		// gives up every so often once the search is no longer wanted
+		if s.Cancel != nil && s.Expanded%cancelInterval == 0 {
+			select {
+			case <-s.Cancel:
+				return
+			default:
+			}
+		}
+
 		// returns the Node having lowest f-cost value(uses min-priority queue)
-		currentNode = heap.Pop(s.openlist.queue).(Node)
+		currentNode = s.openlist.queue.pop()
 
-		if count == 0 {
-			start = currentNode.state
+		// outdated copy of a node that was reached again with a lower cost
+		if s.closelist.table[currentNode.state] {
+			continue
 		}
 
-		// goal found, generating path from start to goal state
-		if currentNode.state == s.Goal {
-			state := s.Goal
-			for s.relation[state] != start {
-				state = s.relation[state]
-				s.Path.PushFront(state)
+		// no remaining node can improve the solution through the cached board
+		if bound >= 0 && currentNode.fCost >= bound {
+			break
+		}
+
+		if s.Tracer != nil {
+			var parent board.Board
+			if currentNode.parent != nil {
+				parent = currentNode.parent.state
 			}
-			s.Path.PushBack(s.Goal)
+			s.Tracer.record(*currentNode, parent, currentNode.parent == nil)
+		}
 
-			s.Moves = s.Path.Len()
-			s.Solved = true
+		// goal found, generating path from start to goal state
+		if s.reached(currentNode.state) {
+			s.finish(s.trace(currentNode))
+			return
+		}
 
-			break
+		// rest of the way is already known for a cached board
+		if cache != nil {
+			if suffix, ok := cache.Path(currentNode.state); ok && (bound < 0 || currentNode.gCost+suffix.Len() < bound) {
+				bound, boundNode, boundSuffix = currentNode.gCost+suffix.Len(), currentNode, suffix
+			}
 		}
 
+		s.Expanded++
+
 		// shifts low-cost node from open list to close list
-		delete(s.openlist.table, currentNode.state)
 		delete(s.openlist.nodeTable, currentNode.state)
 		// add low-cost node to close list
 		s.closelist.table[currentNode.state] = true
 
 		// nodes adjacent to the current node
-		adjacents := neighbours(currentNode.state)
+		s.adjacent = appendNeighbours(s.adjacent[:0], currentNode.state)
+		adjacents := s.adjacent
 
 		for i := 0; i < len(adjacents); i++ {
 			// adjacent node is in close list
@@ -233,27 +357,142 @@ func (s *Solver) Solve() {
 
 			// adjacent node either unavailable in open list or can be improved
 			adjacentNode := s.openlist.nodeTable[adjacents[i]]
-			if (!s.openlist.table[adjacents[i]]) || (currentNode.gCost+1 < adjacentNode.gCost) {
-				adjacentNode.gCost = currentNode.gCost + 1
-				adjacentNode.hCost = heuristicScore(adjacentNode.state)
-				adjacentNode.fCost = adjacentNode.gCost + adjacentNode.hCost
-				adjacentNode.state = adjacents[i]
+			if adjacentNode == nil || currentNode.gCost+1 < adjacentNode.gCost {
+				node := s.newNode(currentNode, adjacents[i])
+				s.scoring(node, false)
 
-				// adjacent node is not in open list
-				if !s.openlist.table[adjacentNode.state] {
-					node := &Node{parent: &currentNode, state: adjacentNode.state}
-					scoring(node, false)
+				s.openlist.nodeTable[adjacents[i]] = node
 
-					s.openlist.table[adjacents[i]] = true
-					s.openlist.nodeTable[adjacents[i]] = *node
+				// an improved node is pushed again, its outdated copy is skipped later
+				s.openlist.queue.push(node)
+			}
+		}
+	}
 
-					s.relation[adjacents[i]] = currentNode.state
+	// reuses the cached suffix of a previous solution
+	if bound >= 0 {
+		path := s.trace(boundNode)
+		path.PushBackList(boundSuffix)
+		s.finish(path)
+	}
+}
 
-					heap.Push(s.openlist.queue, *node)
-				}
-			}
+// trace returns the path from the start to a node following the parents
+func (s *Solver) trace(node *Node) *list.List {
+	path := list.New()
+
+	for ; node.parent != nil; node = node.parent {
+		path.PushFront(node.state)
+	}
+
+	return path
+}
+
+// finish marks the solver solved with the given path and records it in the cache
+// Only the solutions of an admissible heuristic are known to be optimal and cached.
+func (s *Solver) finish(path *list.List) {
+	s.solved(path)
+
+	if s.Cache != nil && s.partial == nil && admissible(s.heuristic) {
+		s.Cache.Store(s)
+	}
+}
+
+// solved marks the solver solved with the given path, leaving the cache alone
+func (s *Solver) solved(path *list.List) {
+	s.Path = path
+	s.Moves = path.Len()
+	s.Solved = true
+}
+
+// Replan returns a solved solver for a board b one move away from from,
+// reusing the rest of the plan from there instead of searching the state space again
+//
+// rest is the element of the solver's path holding the board after from, nil
+// when from ends the path, and o knows the distances to the solver's goal.
+// Every move changes the optimal distance by exactly one, so after a wrong
+// move (one that is not on any optimal solution) taking the move back and
+// following the rest of the plan is an optimal plan. Moving along the plan
+// keeps the rest of it, another optimal move follows a path of o instead.
+// A board elsewhere, or a partial goal where the distance may stay the same,
+// falls back to a new search. Reused plans are not cached.
+func (s *Solver) Replan(from board.Board, rest *list.Element, b *board.Board, o *Optimal) *Solver {
+	replanned := s.like(b)
+
+	if !s.Solved || s.partial != nil || !isNeighbour(from, *b) || !s.follows(from, rest) {
+		replanned.Solve()
+		return replanned
+	}
+
+	path := list.New()
+	switch {
+	case rest != nil && rest.Value.(board.Board) == *b:
+		// moved along the plan itself, what follows is the plan
+		for e := rest.Next(); e != nil; e = e.Next() {
+			path.PushBack(e.Value)
+		}
+	case o.IsOptimalMove(from, *b):
+		// moved along another optimal solution
+		path = o.Path(*b)
+	default:
+		path.PushBack(from)
+		for e := rest; e != nil; e = e.Next() {
+			path.PushBack(e.Value)
 		}
+	}
+
+	replanned.solved(path)
+
+	return replanned
+}
+
+// Follow returns a solver with the same settings, solved for a board
+// whose optimal path to the goal is already known
+//
+// The path is copied, so neither solver changes the plan of the other.
+func (s *Solver) Follow(b *board.Board, path *list.List) *Solver {
+	followed := s.like(b)
+
+	plan := list.New()
+	plan.PushBackList(path)
+	followed.finish(plan)
+
+	return followed
+}
+
+// like returns an unsolved solver for a board with the same goal and settings
+func (s *Solver) like(b *board.Board) *Solver {
+	solver := newSolver(b, s.partial, s.heuristic)
+	solver.Cache = s.Cache
+	solver.TieBreak = s.TieBreak
+	solver.Queue = s.Queue
+
+	return solver
+}
+
+// follows returns whether rest is where the solver's path goes on from a board on it
+func (s *Solver) follows(from board.Board, rest *list.Element) bool {
+	var before *list.Element
+	if rest != nil {
+		before = rest.Prev()
+	} else {
+		before = s.Path.Back()
+	}
 
-		count++
+	if before == nil {
+		return from == s.Start && (rest == nil || rest == s.Path.Front())
 	}
+
+	return before.Value.(board.Board) == from
+}
+
+// isNeighbour returns whether two configurations are a single move apart
+func isNeighbour(a, b board.Board) bool {
+	for _, n := range neighbours(a) {
+		if n == b {
+			return true
+		}
+	}
+
+	return false
 }
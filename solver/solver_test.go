@@ -0,0 +1,196 @@
+package solver
+
+import (
+	"container/list"
+	"github.com/pravj/puzzl/board"
+	"testing"
+	"time"
+)
+
+// mustParse returns the board of a text, failing the test when it is invalid
+func mustParse(tb testing.TB, text string) *board.Board {
+	b, err := board.Parse(text)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return b
+}
+
+func TestReplan(t *testing.T) {
+	tests := []struct {
+		start string
+		right int
+	}{
+		{"1 2 3 4 5 6 0 7 8", 0},
+		{"1 2 3 4 5 6 0 7 8", 1},
+		{"8 6 7 2 5 4 3 0 1", 0},
+		{"8 6 7 2 5 4 3 0 1", 5},
+		{"8 6 7 2 5 4 3 0 1", 30},
+	}
+
+	optimal := NewOptimal(*board.Goal())
+
+	for _, test := range tests {
+		cache := NewCache(DefaultCacheSize)
+
+		s := NewWithHeuristic(mustParse(t, test.start), Manhattan{})
+		s.Cache = cache
+		s.Solve()
+
+		// right moves along the plan
+		from, rest := s.Start, s.Path.Front()
+		for i := 0; i < test.right; i++ {
+			from, rest = rest.Value.(board.Board), rest.Next()
+		}
+		remaining, _ := optimal.Distance(from)
+
+		// every move, right ones on the plan or off it, and wrong ones
+		for _, next := range neighbours(from) {
+			next := next
+			want := remaining + 1
+			if optimal.IsOptimalMove(from, next) {
+				want = remaining - 1
+			}
+
+			replanned := s.Replan(from, rest, &next, optimal)
+			if replanned.Expanded != 0 {
+				t.Errorf("%v after %v right moves: Replan expanded %v nodes, want none", test.start, test.right, replanned.Expanded)
+			}
+			if !replanned.Solved || replanned.Moves != want {
+				t.Errorf("%v after %v right moves: Replan planned %v moves, want %v", test.start, test.right, replanned.Moves, want)
+			}
+			if !validPath(next, replanned.Path) {
+				t.Errorf("%v after %v right moves: Replan planned an invalid path", test.start, test.right)
+			}
+
+			// the cache only ever holds optimal plans
+			for e := replanned.Path.Front(); e != nil; e = e.Next() {
+				b := e.Value.(board.Board)
+				distance, _ := optimal.Distance(b)
+				if path, ok := cache.Path(b); ok && path.Len() != distance {
+					t.Errorf("%v after %v right moves: cached %v moves for a board %v moves away", test.start, test.right, path.Len(), distance)
+				}
+			}
+			if path, ok := cache.Path(next); ok && path.Len() != want {
+				t.Errorf("%v after %v right moves: cached %v moves for a board %v moves away", test.start, test.right, path.Len(), want)
+			}
+		}
+	}
+}
+
+func TestReplanElsewhere(t *testing.T) {
+	s := NewWithHeuristic(mustParse(t, "8 6 7 2 5 4 3 0 1"), Manhattan{})
+	s.Solve()
+
+	// a board two moves away from the start is not one move away from the plan
+	far := s.Path.Front().Next().Value.(board.Board)
+	b := mustParse(t, "1 2 3 4 5 6 0 7 8")
+
+	replanned := s.Replan(far, s.Path.Front(), b, NewOptimal(*board.Goal()))
+	if replanned.Expanded == 0 || replanned.Moves != 2 {
+		t.Errorf("Replan of an unrelated board: %v moves after %v expansions, want a new search for 2 moves", replanned.Moves, replanned.Expanded)
+	}
+}
+
+// validPath returns whether a path leads from a board to the goal one move at a time
+func validPath(from board.Board, path *list.List) bool {
+	for e := path.Front(); e != nil; e = e.Next() {
+		if !isNeighbour(from, e.Value.(board.Board)) {
+			return false
+		}
+		from = e.Value.(board.Board)
+	}
+
+	return from == *board.Goal()
+}
+
+func TestSolveCancel(t *testing.T) {
+	cancel := make(chan struct{})
+	close(cancel)
+
+	s := New(mustParse(t, "8 6 7 2 5 4 3 0 1"))
+	s.Cancel = cancel
+	s.Solve()
+
+	if s.Solved || s.Expanded > cancelInterval {
+		t.Errorf("cancelled Solve: solved %v after %v expansions, want an early stop", s.Solved, s.Expanded)
+	}
+}
+
+func TestPresolverLookup(t *testing.T) {
+	b := mustParse(t, "1 2 3 4 5 6 0 7 8")
+
+	p := NewPresolver()
+	p.Heuristic = Manhattan{}
+	p.Prefetch(*b)
+
+	var s *Solver
+	for ok := false; !ok; s, ok = p.Lookup(*b) {
+		time.Sleep(time.Millisecond)
+	}
+
+	// changing a looked up plan leaves the presolved one alone
+	s.Path.Init()
+
+	again, _ := p.Lookup(*b)
+	if again.Path.Len() != 2 || again.Moves != 2 {
+		t.Errorf("Lookup after changing a copy: %v moves on a path of %v, want 2", again.Moves, again.Path.Len())
+	}
+	if again.heuristic != (Manhattan{}) {
+		t.Errorf("Lookup: presolved with %T, want the presolver's heuristic", again.heuristic)
+	}
+}
+
+func TestTeachingStagesFor(t *testing.T) {
+	stages, err := TeachingStagesFor(board.SIZE, board.SIZE)
+	if err != nil || len(stages) != 2 || !stages[1].Reached(*board.Goal()) {
+		t.Errorf("TeachingStagesFor(%v, %v): %v stages, error %v", board.SIZE, board.SIZE, len(stages), err)
+	}
+
+	if _, err := TeachingStagesFor(4, 4); err == nil {
+		t.Error("TeachingStagesFor(4, 4): no error for a board other than 3x3")
+	}
+}
+
+func TestDefaultTieBreak(t *testing.T) {
+	if tie := New(board.Goal()).TieBreak; tie != DefaultTieBreak {
+		t.Errorf("New: tie-breaking %v, want %v", tie, DefaultTieBreak)
+	}
+	if tie := NewPresolver().TieBreak; tie != DefaultTieBreak {
+		t.Errorf("NewPresolver: tie-breaking %v, want %v", tie, DefaultTieBreak)
+	}
+
+	for _, name := range tieBreakNames {
+		if tie, err := ParseTieBreak(name); err != nil || tie.String() != name {
+			t.Errorf("ParseTieBreak(%q) = %v, %v", name, tie, err)
+		}
+	}
+}
+
+func TestScoringAllocations(t *testing.T) {
+	for _, h := range []Heuristic{MisplacedTiles{}, Manhattan{}, WalkingDistance{}} {
+		s := NewWithHeuristic(mustParse(t, "8 6 7 2 5 4 3 0 1"), h)
+		node := &Node{state: s.Start}
+
+		if allocs := testing.AllocsPerRun(100, func() { s.scoring(node, true) }); allocs != 0 {
+			t.Errorf("scoring with %T allocates %v times per node, want none", h, allocs)
+		}
+	}
+}
+
+func TestSolveAllocations(t *testing.T) {
+	start := mustParse(t, "8 6 7 2 5 4 3 0 1")
+
+	var expanded int
+	allocs := testing.AllocsPerRun(1, func() {
+		s := NewWithHeuristic(start, MisplacedTiles{})
+		s.Solve()
+		expanded = s.Expanded
+	})
+
+	// nodes come from the solver's pool, only the maps and queue grow now and then
+	if allocs > float64(expanded/20) {
+		t.Errorf("Solve allocates %v times for %v expanded nodes, want nodes pooled", allocs, expanded)
+	}
+}
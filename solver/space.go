@@ -0,0 +1,177 @@
+package solver
+
+import (
+	"github.com/pravj/puzzl/board"
+)
+
+// unvisited marks a configuration that can not reach the goal
+const unvisited byte = 255
+
+// Space holds the exact goal distance of every configuration of a board shape
+//
+// Configurations are stored by their permutation rank, a single byte each,
+// and nothing else grows with the shape. A 3x4 board needs 12! bytes, about
+// half a gigabyte, and larger shapes are out of reach.
+type Space struct {
+	Rows int
+	Cols int
+
+	distance []byte
+
+	// number of configurations at each distance from the goal
+	Counts []int
+}
+
+// Explore runs an exhaustive breadth first search from the goal of a rows*cols board
+//
+// Instead of keeping the configurations of a layer, each layer is found by
+// scanning the distances for the ones of the previous depth.
+func Explore(rows, cols int) *Space {
+	n := rows * cols
+	s := &Space{Rows: rows, Cols: cols, distance: make([]byte, factorial(n))}
+
+	for i := range s.distance {
+		s.distance[i] = unvisited
+	}
+
+	goal := board.NewGrid(rows, cols)
+	s.distance[rank(goal.Tiles)] = 0
+	s.Counts = []int{1}
+
+	tiles := make([]int, n)
+
+	for depth := 0; s.Counts[depth] > 0; depth++ {
+		var count int
+
+		for r, d := range s.distance {
+			if int(d) != depth {
+				continue
+			}
+			unrank(r, tiles)
+
+			// slides each neighbouring tile into the blank and back again
+			blank := indexOf(tiles, 0)
+			col := blank % cols
+			for _, target := range [...]int{blank - cols, blank + cols, blank - 1, blank + 1} {
+				if target < 0 || target >= n || (target == blank-1 && col == 0) || (target == blank+1 && col == cols-1) {
+					continue
+				}
+
+				tiles[blank], tiles[target] = tiles[target], 0
+				if nr := rank(tiles); s.distance[nr] == unvisited {
+					s.distance[nr] = byte(depth + 1)
+					count++
+				}
+				tiles[target], tiles[blank] = tiles[blank], 0
+			}
+		}
+
+		s.Counts = append(s.Counts, count)
+	}
+
+	// the last layer is always empty
+	s.Counts = s.Counts[:len(s.Counts)-1]
+
+	return s
+}
+
+// indexOf returns the position of a value among the tiles, -1 when missing
+func indexOf(tiles []int, v int) int {
+	for i, t := range tiles {
+		if t == v {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Depth returns the largest distance from the goal, God's number of the shape
+func (s *Space) Depth() int {
+	return len(s.Counts) - 1
+}
+
+// Distance returns the optimal number of moves from a configuration to the goal
+// second value is false when the goal can not be reached from it
+func (s *Space) Distance(g board.Grid) (int, bool) {
+	d := s.distance[rank(g.Tiles)]
+	if d == unvisited {
+		return -1, false
+	}
+
+	return int(d), true
+}
+
+// Each calls visit for every configuration that can reach the goal,
+// the grid passed to visit is reused and must be copied to be kept
+func (s *Space) Each(visit func(g board.Grid, distance int)) {
+	tiles := make([]int, s.Rows*s.Cols)
+
+	for r, d := range s.distance {
+		if d == unvisited {
+			continue
+		}
+
+		unrank(r, tiles)
+		visit(board.Grid{Rows: s.Rows, Cols: s.Cols, Tiles: tiles}, int(d))
+	}
+}
+
+// factorial returns n!
+func factorial(n int) int {
+	f := 1
+	for i := 2; i <= n; i++ {
+		f *= i
+	}
+
+	return f
+}
+
+// rank returns the lexicographic index of a permutation of 0..n-1
+func rank(tiles []int) int {
+	var r int
+	n := len(tiles)
+
+	for i := 0; i < n; i++ {
+		smaller := 0
+		for j := i + 1; j < n; j++ {
+			if tiles[j] < tiles[i] {
+				smaller++
+			}
+		}
+
+		r = r*(n-i) + smaller
+	}
+
+	return r
+}
+
+// unrank fills tiles with the permutation having the given lexicographic index
+func unrank(r int, tiles []int) {
+	n := len(tiles)
+
+	// factorial number system digits, least significant first, ranks
+	// fit in an int only up to 20 tiles
+	var digits [20]int
+	for i := 1; i <= n; i++ {
+		digits[n-i] = r % i
+		r /= i
+	}
+
+	var used [20]bool
+	for i := 0; i < n; i++ {
+		k := digits[i]
+		for v := 0; v < n; v++ {
+			if used[v] {
+				continue
+			}
+
+			if k == 0 {
+				tiles[i] = v
+				used[v] = true
+				break
+			}
+			k--
+		}
+	}
+}
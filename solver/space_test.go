@@ -0,0 +1,41 @@
+package solver
+
+import (
+	"github.com/pravj/puzzl/board"
+	"testing"
+)
+
+func TestExplore(t *testing.T) {
+	tests := []struct {
+		rows, cols int
+		states     int
+		depth      int
+	}{
+		{2, 2, 12, 6},
+		{2, 3, 360, 21},
+		{3, 3, 181440, 31},
+	}
+
+	for _, test := range tests {
+		s := Explore(test.rows, test.cols)
+
+		var states int
+		for _, c := range s.Counts {
+			states += c
+		}
+		if states != test.states || s.Depth() != test.depth {
+			t.Errorf("%vx%v: %v states up to %v moves away, want %v up to %v", test.rows, test.cols, states, s.Depth(), test.states, test.depth)
+		}
+	}
+
+	// distances agree with the ones of a search from the goal
+	s, o := Explore(board.SIZE, board.SIZE), NewOptimal(*board.Goal())
+	for _, text := range []string{"1 2 3 4 5 6 7 8 0", "1 2 3 4 5 6 0 7 8", "8 6 7 2 5 4 3 0 1", "1 2 3 4 0 8 7 6 5"} {
+		b := mustParse(t, text)
+
+		want, _ := o.Distance(*b)
+		if d, ok := s.Distance(b.Grid()); !ok || d != want {
+			t.Errorf("%v: distance %v, want %v", text, d, want)
+		}
+	}
+}
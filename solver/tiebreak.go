@@ -0,0 +1,53 @@
+package solver
+
+import (
+	"fmt"
+)
+
+// TieBreak represents the order in which nodes having the same f-cost are expanded
+type TieBreak int
+
+// Tie-breaking policies, all but TieNone make the expansion order reproducible
+// The first one is the zero value, so every solver uses DefaultTieBreak unless told otherwise.
+const (
+	// TieHighG prefers the deeper node, closer to a goal
+	TieHighG TieBreak = iota
+
+	// TieLowH prefers the node with the lower heuristic estimate
+	TieLowH
+
+	// TieFIFO prefers the node that was added first
+	TieFIFO
+
+	// TieLIFO prefers the node that was added last
+	TieLIFO
+
+	// TieNone leaves equal nodes in whatever order the queue keeps them
+	TieNone
+)
+
+// DefaultTieBreak is the tie-breaking policy of solvers, presolvers and batches unless another one is set
+const DefaultTieBreak TieBreak = TieHighG
+
+// tieBreakNames holds the name of each tie-breaking policy
+var tieBreakNames = [...]string{"high-g", "low-h", "fifo", "lifo", "none"}
+
+// String returns the name of a tie-breaking policy
+func (t TieBreak) String() string {
+	if t < TieHighG || t > TieNone {
+		return fmt.Sprintf("TieBreak(%d)", int(t))
+	}
+
+	return tieBreakNames[t]
+}
+
+// ParseTieBreak returns the tie-breaking policy for its name
+func ParseTieBreak(name string) (TieBreak, error) {
+	for i, n := range tieBreakNames {
+		if n == name {
+			return TieBreak(i), nil
+		}
+	}
+
+	return DefaultTieBreak, fmt.Errorf("solver: unknown tie-breaking policy %q, available: %v", name, tieBreakNames)
+}
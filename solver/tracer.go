@@ -0,0 +1,120 @@
+package solver
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/pravj/puzzl/board"
+	"io"
+	"strings"
+)
+
+// Tracer records the nodes expanded by a solver, to show what the search explored
+type Tracer struct {
+	// MaxDepth keeps only the nodes within that many moves of the start, 0 keeps all
+	MaxDepth int
+
+	nodes    []tracedNode
+	expanded int
+}
+
+// tracedNode is an expanded node along with its costs and parent
+type tracedNode struct {
+	state  board.Board
+	parent board.Board
+	root   bool
+	order  int
+
+	gCost int
+	hCost int
+	fCost int
+}
+
+// NewTracer returns pointer to a Tracer instance with the given depth cap
+func NewTracer(maxDepth int) *Tracer {
+	return &Tracer{MaxDepth: maxDepth}
+}
+
+// Len returns the number of recorded nodes
+func (t *Tracer) Len() int {
+	return len(t.nodes)
+}
+
+// record adds an expanded node unless it lies deeper than the cap
+func (t *Tracer) record(node Node, parent board.Board, root bool) {
+	t.expanded++
+	if t.MaxDepth > 0 && node.gCost > t.MaxDepth {
+		return
+	}
+
+	t.nodes = append(t.nodes, tracedNode{state: node.state, parent: parent, root: root, order: t.expanded, gCost: node.gCost, hCost: node.hCost, fCost: node.fCost})
+}
+
+// WriteDOT writes the recorded nodes as a Graphviz DOT graph
+// Nodes are labelled with the board, expansion order and costs,
+// the nodes and edges of the solver's solution path are highlighted.
+func (t *Tracer) WriteDOT(w io.Writer, s *Solver) error {
+	// boards on the solution path, along with their successors on it
+	onPath := make(map[board.Board]bool)
+	pathEdge := make(map[[2]board.Board]bool)
+	if s.Solved {
+		previous := s.Start
+		onPath[previous] = true
+
+		for e := s.Path.Front(); e != nil; e = e.Next() {
+			state := e.Value.(board.Board)
+			onPath[state] = true
+			pathEdge[[2]board.Board{previous, state}] = true
+			previous = state
+		}
+	}
+
+	ids := make(map[board.Board]int)
+	for i, n := range t.nodes {
+		ids[n.state] = i
+	}
+
+	writer := bufio.NewWriter(w)
+	fmt.Fprintln(writer, "digraph search {")
+	fmt.Fprintln(writer, "  node [shape=box, fontname=monospace];")
+
+	for i, n := range t.nodes {
+		label := fmt.Sprintf("%v\\n#%v g=%v h=%v f=%v", rowsLabel(n.state), n.order, n.gCost, n.hCost, n.fCost)
+
+		style := ""
+		if onPath[n.state] {
+			style = ", color=red, penwidth=2"
+		}
+
+		fmt.Fprintf(writer, "  n%v [label=\"%v\"%v];\n", i, label, style)
+	}
+
+	for i, n := range t.nodes {
+		parent, ok := ids[n.parent]
+		if n.root || !ok {
+			continue
+		}
+
+		style := ""
+		if pathEdge[[2]board.Board{n.parent, n.state}] {
+			style = " [color=red, penwidth=2]"
+		}
+
+		fmt.Fprintf(writer, "  n%v -> n%v%v;\n", parent, i, style)
+	}
+
+	fmt.Fprintln(writer, "}")
+
+	return writer.Flush()
+}
+
+// rowsLabel returns a board as text with one line per row for a DOT label
+func rowsLabel(b board.Board) string {
+	values := strings.Fields(b.String())
+
+	var rows []string
+	for i := 0; i < board.SIZE; i++ {
+		rows = append(rows, strings.Join(values[i*board.SIZE:(i+1)*board.SIZE], " "))
+	}
+
+	return strings.Join(rows, "\\n")
+}
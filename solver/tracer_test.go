@@ -0,0 +1,72 @@
+package solver
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestWriteDOT(t *testing.T) {
+	tests := []struct {
+		start string
+		depth int
+
+		// recorded nodes, those on the path and the edges along it, nodes
+		// off the path are expanded too when the count is -1
+		nodes, pathNodes, pathEdges int
+	}{
+		{"1 2 3 4 5 6 0 7 8", 0, 3, 3, 2},
+		{"1 2 3 4 5 6 0 7 8", 1, 2, 2, 1},
+		{"1 2 3 4 0 8 7 6 5", 2, -1, 3, 2},
+	}
+
+	for _, test := range tests {
+		s := NewWithHeuristic(mustParse(t, test.start), Manhattan{})
+		s.Tracer, s.TieBreak = NewTracer(test.depth), TieFIFO
+		s.Solve()
+
+		var out bytes.Buffer
+		if err := s.Tracer.WriteDOT(&out, s); err != nil {
+			t.Fatal(err)
+		}
+
+		var nodes, pathNodes, edges, pathEdges int
+		for _, line := range strings.Split(out.String(), "\n") {
+			red := strings.Contains(line, "color=red")
+
+			switch {
+			case strings.Contains(line, " -> "):
+				edges++
+				if red {
+					pathEdges++
+				}
+			case strings.Contains(line, "[label="):
+				nodes++
+				if red {
+					pathNodes++
+				}
+
+				if deeper := fmt.Sprintf(" g=%v ", test.depth+1); test.depth > 0 && strings.Contains(line, deeper) {
+					t.Errorf("depth %v: node deeper than the cap %q", test.depth, line)
+				}
+			}
+		}
+
+		if test.nodes < 0 {
+			test.nodes = s.Tracer.Len()
+			if nodes <= pathNodes {
+				t.Errorf("depth %v: only the path of %v written", test.depth, test.start)
+			}
+		}
+		if s.Tracer.Len() != test.nodes || nodes != test.nodes || edges != test.nodes-1 {
+			t.Errorf("depth %v: %v recorded, %v nodes and %v edges written, want %v nodes", test.depth, s.Tracer.Len(), nodes, edges, test.nodes)
+		}
+		if pathNodes != test.pathNodes || pathEdges != test.pathEdges {
+			t.Errorf("depth %v: %v nodes and %v edges highlighted, want %v and %v", test.depth, pathNodes, pathEdges, test.pathNodes, test.pathEdges)
+		}
+		if !strings.HasPrefix(out.String(), "digraph search {") || !strings.HasSuffix(out.String(), "}\n") {
+			t.Errorf("depth %v: not a DOT graph:\n%v", test.depth, out.String())
+		}
+	}
+}
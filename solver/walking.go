@@ -0,0 +1,169 @@
+package solver
+
+import (
+	"fmt"
+	"github.com/pravj/puzzl/board"
+	"sync"
+)
+
+// WalkingDistance implements the walking distance heuristic
+//
+// The board is looked at line by line, only counting how many tiles of each
+// goal row are present in every row. Moving the blank up or down walks a tile
+// to the next row, the least number of such walks to sort all the counts is
+// found from a precomputed table. The same is done for columns and both
+// values are added, since a vertical move never changes the column counts.
+// It accounts for tiles blocking each other in a line, which Manhattan
+// distance ignores, and works for boards of 2 to 4 rows and columns, like
+// 3x3 and 4x4 ones.
+type WalkingDistance struct{}
+
+// Range of lines and tiles per line walking distance tables are built for
+const (
+	minWalkingLines int = 2
+	maxWalkingLines int = 4
+)
+
+// walkingTable maps encoded line counts to their walking distance
+type walkingTable map[uint64]int
+
+// walkingTables holds a table per number of lines and tiles per line,
+// each one built once when it is first needed and only read afterwards
+var walkingTables [maxWalkingLines - minWalkingLines + 1][maxWalkingLines - minWalkingLines + 1]struct {
+	once  sync.Once
+	table walkingTable
+}
+
+// Supports returns an error for the board shapes walking distance has no tables for
+func (WalkingDistance) Supports(rows, cols int) error {
+	if rows < minWalkingLines || rows > maxWalkingLines || cols < minWalkingLines || cols > maxWalkingLines {
+		return fmt.Errorf("solver: walking distance works for boards of %v to %v rows and columns, not %vx%v",
+			minWalkingLines, maxWalkingLines, rows, cols)
+	}
+
+	return nil
+}
+
+// walkingKey encodes line counts and the line having the blank tile
+func walkingKey(counts []int, perLine, blankLine int) uint64 {
+	var key uint64
+
+	for _, c := range counts {
+		key = key*uint64(perLine+1) + uint64(c)
+	}
+
+	return key*uint64(len(counts)) + uint64(blankLine)
+}
+
+// tableFor returns the walking distance table for a supported shape, building it once
+func tableFor(lines, perLine int) walkingTable {
+	shape := &walkingTables[lines-minWalkingLines][perLine-minWalkingLines]
+	shape.once.Do(func() {
+		shape.table = buildWalkingTable(lines, perLine)
+	})
+
+	return shape.table
+}
+
+// walkingState is a node of the breadth first search over line counts
+type walkingState struct {
+	counts    []int
+	blankLine int
+}
+
+// buildWalkingTable runs a breadth first search from the goal counts
+// counts[i*lines+k] is the number of tiles in line i which belong to line k
+func buildWalkingTable(lines, perLine int) walkingTable {
+	goal := make([]int, lines*lines)
+	for i := 0; i < lines; i++ {
+		goal[i*lines+i] = perLine
+	}
+	// last line holds the blank tile in the goal
+	goal[lines*lines-1]--
+
+	table := walkingTable{walkingKey(goal, perLine, lines-1): 0}
+	layer := []walkingState{{counts: goal, blankLine: lines - 1}}
+
+	for depth := 1; len(layer) > 0; depth++ {
+		var next []walkingState
+
+		for _, state := range layer {
+			for _, line := range []int{state.blankLine - 1, state.blankLine + 1} {
+				if line < 0 || line >= lines {
+					continue
+				}
+
+				// walks a tile of any group from that line into the blank's line
+				for k := 0; k < lines; k++ {
+					if state.counts[line*lines+k] == 0 {
+						continue
+					}
+
+					counts := make([]int, len(state.counts))
+					copy(counts, state.counts)
+					counts[line*lines+k]--
+					counts[state.blankLine*lines+k]++
+
+					key := walkingKey(counts, perLine, line)
+					if _, ok := table[key]; !ok {
+						table[key] = depth
+						next = append(next, walkingState{counts: counts, blankLine: line})
+					}
+				}
+			}
+		}
+
+		layer = next
+	}
+
+	return table
+}
+
+// Estimate adds the vertical and horizontal walking distances
+// It panics for a shape that Supports rejects.
+func (w WalkingDistance) Estimate(g board.Grid) int {
+	if err := w.Supports(g.Rows, g.Cols); err != nil {
+		panic(err)
+	}
+
+	var vertical, horizontal [maxWalkingLines * maxWalkingLines]int
+
+	var blank int
+	for i, v := range g.Tiles {
+		if v == 0 {
+			blank = i
+			continue
+		}
+
+		target := g.Target(v)
+		vertical[(i/g.Cols)*g.Rows+target/g.Cols]++
+		horizontal[(i%g.Cols)*g.Cols+target%g.Cols]++
+	}
+
+	rows := tableFor(g.Rows, g.Cols)[walkingKey(vertical[:g.Rows*g.Rows], g.Cols, blank/g.Cols)]
+	cols := tableFor(g.Cols, g.Rows)[walkingKey(horizontal[:g.Cols*g.Cols], g.Rows, blank%g.Cols)]
+
+	return rows + cols
+}
+
+// maxHeuristic combines heuristics by taking the largest estimate
+type maxHeuristic []Heuristic
+
+// Estimate returns the largest of all the estimates
+func (m maxHeuristic) Estimate(g board.Grid) int {
+	var score int
+
+	for _, h := range m {
+		if e := h.Estimate(g); e > score {
+			score = e
+		}
+	}
+
+	return score
+}
+
+// Max returns a heuristic that takes the largest estimate of the given ones,
+// it stays admissible when all of them are
+func Max(hs ...Heuristic) Heuristic {
+	return maxHeuristic(hs)
+}
@@ -0,0 +1,74 @@
+package solver
+
+import (
+	"github.com/pravj/puzzl/board"
+	"testing"
+)
+
+func TestWalkingDistanceSupports(t *testing.T) {
+	tests := []struct {
+		rows, cols int
+		supported  bool
+	}{
+		{3, 3, true},
+		{4, 4, true},
+		{2, 3, true},
+		{2, 4, true},
+		{5, 5, false},
+		{1, 4, false},
+	}
+
+	for _, test := range tests {
+		err := WalkingDistance{}.Supports(test.rows, test.cols)
+		if (err == nil) != test.supported {
+			t.Errorf("Supports(%v, %v) = %v, want supported %v", test.rows, test.cols, err, test.supported)
+		}
+		if err := CheckShape(Max(Manhattan{}, WalkingDistance{}), test.rows, test.cols); (err == nil) != test.supported {
+			t.Errorf("CheckShape of walking,manhattan on %vx%v = %v, want supported %v", test.rows, test.cols, err, test.supported)
+		}
+	}
+}
+
+func TestWalkingDistanceEstimate(t *testing.T) {
+	tests := []struct {
+		grid     string
+		estimate int
+	}{
+		{"1 2 3 4 5 6 7 8 0", 0},
+		{"1 2 3 4 5 6 7 0 8", 1},
+		{"8 6 7 2 5 4 3 0 1", 25},
+		{"1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 0", 0},
+	}
+
+	for _, test := range tests {
+		g, err := board.ParseGrid(test.grid)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if estimate := (WalkingDistance{}).Estimate(g); estimate != test.estimate {
+			t.Errorf("Estimate(%v) = %v, want %v", test.grid, estimate, test.estimate)
+		}
+
+		allocs := testing.AllocsPerRun(100, func() {
+			WalkingDistance{}.Estimate(g)
+		})
+		if allocs != 0 {
+			t.Errorf("Estimate(%v) allocates %v times, want none", test.grid, allocs)
+		}
+	}
+}
+
+func BenchmarkWalkingDistance(b *testing.B) {
+	g, err := board.ParseGrid("15 14 13 12 11 10 9 8 7 6 5 4 3 2 1 0")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			WalkingDistance{}.Estimate(g)
+		}
+	})
+}
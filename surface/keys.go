@@ -0,0 +1,380 @@
+package surface
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/nsf/termbox-go"
+	"github.com/pravj/puzzl/game"
+	"github.com/pravj/puzzl/locale"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultBindings is the key binding preset used unless another one is asked for
+const DefaultBindings string = "arrows"
+
+// Command is what a key does, a game action or something of the surface itself
+type Command int
+
+// Commands in the order they are listed by the help overlay
+const (
+	CommandUp Command = iota
+	CommandDown
+	CommandLeft
+	CommandRight
+	CommandHint
+	CommandUndo
+	CommandHistory
+	CommandHelp
+	CommandQuit
+)
+
+// commandNames holds the name of each command, as written in key binding files
+var commandNames = [...]string{"up", "down", "left", "right", "hint", "undo", "history", "help", "quit"}
+
+// String returns the name of a command
+func (c Command) String() string {
+	if c < CommandUp || c > CommandQuit {
+		return fmt.Sprintf("Command(%d)", int(c))
+	}
+
+	return commandNames[c]
+}
+
+// Key is a key press, a special key or a character
+type Key struct {
+	Key termbox.Key
+	Ch  rune
+}
+
+// specialKey is a key without a character, along with its names
+type specialKey struct {
+	key termbox.Key
+
+	// name in key binding files and label in the help overlay,
+	// the label is looked up in the catalog and shown as it is when not found
+	name  string
+	label string
+}
+
+// specialKeys lists the keys that can be bound besides characters
+var specialKeys = []specialKey{
+	{termbox.KeyArrowUp, "arrow-up", "↑"},
+	{termbox.KeyArrowDown, "arrow-down", "↓"},
+	{termbox.KeyArrowLeft, "arrow-left", "←"},
+	{termbox.KeyArrowRight, "arrow-right", "→"},
+	{termbox.KeyEsc, "esc", "key-esc"},
+	{termbox.KeyEnter, "enter", "key-enter"},
+	{termbox.KeySpace, "space", "key-space"},
+	{termbox.KeyTab, "tab", "key-tab"},
+	{termbox.KeyBackspace2, "backspace", "key-backspace"},
+	{termbox.KeyF1, "f1", "F1"},
+	{termbox.KeyF2, "f2", "F2"},
+	{termbox.KeyF3, "f3", "F3"},
+	{termbox.KeyF4, "f4", "F4"},
+	{termbox.KeyF5, "f5", "F5"},
+	{termbox.KeyF6, "f6", "F6"},
+	{termbox.KeyF7, "f7", "F7"},
+	{termbox.KeyF8, "f8", "F8"},
+	{termbox.KeyF9, "f9", "F9"},
+	{termbox.KeyF10, "f10", "F10"},
+	{termbox.KeyF11, "f11", "F11"},
+	{termbox.KeyF12, "f12", "F12"},
+}
+
+// ParseKey returns the key for a name of a special key or a single character
+func ParseKey(name string) (Key, error) {
+	for _, k := range specialKeys {
+		if k.name == strings.ToLower(name) {
+			return Key{Key: k.key}, nil
+		}
+	}
+
+	if utf8.RuneCountInString(name) == 1 {
+		ch, _ := utf8.DecodeRuneInString(name)
+		return Key{Ch: ch}, nil
+	}
+
+	return Key{}, fmt.Errorf("surface: unknown key %q, a character or one of arrow-up, esc, enter, space, tab, backspace, f1 and so on expected", name)
+}
+
+// String returns the label of a key in the default language
+func (k Key) String() string {
+	return k.Label(locale.Lookup(locale.DefaultLanguage))
+}
+
+// Label returns the label of a key in the help overlay, translated by the catalog
+func (k Key) Label(catalog *locale.Catalog) string {
+	if k.Ch != 0 {
+		return string(k.Ch)
+	}
+
+	for _, s := range specialKeys {
+		if s.key == k.Key {
+			return catalog.Text(s.label)
+		}
+	}
+
+	return fmt.Sprintf("Key(%d)", int(k.Key))
+}
+
+// Bindings maps keys to commands
+type Bindings struct {
+	Name string
+	Keys map[Key]Command
+
+	// direction keys move a tile into the blank instead of moving the blank
+	Invert bool
+}
+
+// bind returns bindings of the special keys and of every character given for a command
+func bind(name string, commands map[Command]string, special map[termbox.Key]Command) *Bindings {
+	b := &Bindings{Name: name, Keys: make(map[Key]Command)}
+
+	for k, c := range special {
+		b.Keys[Key{Key: k}] = c
+	}
+	for c, chars := range commands {
+		for _, ch := range chars {
+			b.Keys[Key{Ch: ch}] = c
+		}
+	}
+
+	return b
+}
+
+// KeyPresets lists the built-in key bindings by name
+var KeyPresets = map[string]*Bindings{
+	// arrows move, h asks for a hint
+	"arrows": bind("arrows", map[Command]string{
+		CommandHint:    "hH",
+		CommandUndo:    "uU",
+		CommandHistory: "nN",
+		CommandHelp:    "?",
+	}, arrowKeys),
+
+	// w, a, s and d move, all at hand with e for a hint and z to undo
+	"wasd": bind("wasd", map[Command]string{
+		CommandUp:      "wW",
+		CommandLeft:    "aA",
+		CommandDown:    "sS",
+		CommandRight:   "dD",
+		CommandHint:    "eE",
+		CommandUndo:    "zZ",
+		CommandHistory: "nN",
+		CommandHelp:    "?",
+		CommandQuit:    "qQ",
+	}, arrowKeys),
+
+	// h, j, k and l move like in vim, so the hint moves over to i
+	"vim": bind("vim", map[Command]string{
+		CommandLeft:    "h",
+		CommandDown:    "j",
+		CommandUp:      "k",
+		CommandRight:   "l",
+		CommandHint:    "i",
+		CommandUndo:    "u",
+		CommandHistory: "n",
+		CommandHelp:    "?",
+		CommandQuit:    "q",
+	}, arrowKeys),
+}
+
+// arrowKeys holds the special keys of every preset
+var arrowKeys = map[termbox.Key]Command{
+	termbox.KeyArrowUp:    CommandUp,
+	termbox.KeyArrowDown:  CommandDown,
+	termbox.KeyArrowLeft:  CommandLeft,
+	termbox.KeyArrowRight: CommandRight,
+	termbox.KeyEsc:        CommandQuit,
+}
+
+// KeyPresetNames returns the sorted names of the built-in key bindings
+func KeyPresetNames() []string {
+	var names []string
+	for n := range KeyPresets {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Command returns the command of a key event, if the key is bound
+func (b *Bindings) Command(ev termbox.Event) (Command, bool) {
+	k := Key{Key: ev.Key}
+	if ev.Ch != 0 {
+		k = Key{Ch: ev.Ch}
+	}
+
+	c, ok := b.Keys[k]
+	return c, ok
+}
+
+// opposites holds the game action moving the blank against each direction command
+var opposites = [...]game.Action{
+	CommandUp:    game.MoveDown,
+	CommandDown:  game.MoveUp,
+	CommandLeft:  game.MoveRight,
+	CommandRight: game.MoveLeft,
+}
+
+// Action returns the game action of a command other than history and help
+func (b *Bindings) Action(c Command) game.Action {
+	switch c {
+	case CommandUp, CommandDown, CommandLeft, CommandRight:
+		if b.Invert {
+			return opposites[c]
+		}
+
+		// direction commands are in the same order as the moves
+		return game.Action(c)
+	case CommandHint:
+		return game.AskHint
+	case CommandUndo:
+		return game.TakeBack
+	}
+
+	return game.Quit
+}
+
+// KeyLabels returns the labels of the keys bound to a command translated by the catalog,
+// special keys first
+func (b *Bindings) KeyLabels(c Command, catalog *locale.Catalog) []string {
+	var labels []string
+	for _, k := range specialKeys {
+		if command, ok := b.Keys[Key{Key: k.key}]; ok && command == c {
+			labels = append(labels, catalog.Text(k.label))
+		}
+	}
+
+	var chars []string
+	for k, command := range b.Keys {
+		if command == c && k.Ch != 0 {
+			chars = append(chars, k.String())
+		}
+	}
+	sort.Strings(chars)
+
+	return append(labels, chars...)
+}
+
+// OpenBindings returns the built-in key bindings of a name, or the bindings of a file otherwise
+func OpenBindings(name string) (*Bindings, error) {
+	if b, ok := KeyPresets[name]; ok {
+		return b, nil
+	}
+
+	file, err := os.Open(name)
+	if os.IsNotExist(err) && !strings.ContainsAny(name, "./") {
+		return nil, fmt.Errorf("surface: unknown key bindings %q, available: %v or a key binding file", name, KeyPresetNames())
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	b, err := ParseBindings(file)
+	if err != nil {
+		return nil, fmt.Errorf("%v (%v)", err, name)
+	}
+	b.Name = name
+
+	return b, nil
+}
+
+// ParseBindings reads a key binding file of "key = command" lines,
+// blank lines and lines starting with # are skipped
+//
+// Keys keep the command of the base preset, arrows by default, unless they
+// are bound again or bound to none, a key is bound only once in a file.
+// "invert = true" makes direction keys move a tile into the blank.
+func ParseBindings(r io.Reader) (*Bindings, error) {
+	base := DefaultBindings
+	invert := false
+	keys := make(map[Key]Command)
+	unbound := make(map[Key]bool)
+
+	// line of each bound key
+	lines := make(map[Key]int)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		// the key itself may be =, the last one separates the command
+		i := strings.LastIndex(text, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("surface: line %v: \"key = command\" expected", line)
+		}
+		name, value := strings.TrimSpace(text[:i]), strings.ToLower(strings.TrimSpace(text[i+1:]))
+
+		switch name {
+		case "base":
+			if _, ok := KeyPresets[value]; !ok {
+				return nil, fmt.Errorf("surface: line %v: unknown base key bindings %q, available: %v", line, value, KeyPresetNames())
+			}
+			base = value
+			continue
+		case "invert":
+			if value != "true" && value != "false" {
+				return nil, fmt.Errorf("surface: line %v: invert is true or false", line)
+			}
+			invert = value == "true"
+			continue
+		}
+
+		k, err := ParseKey(name)
+		if err != nil {
+			return nil, fmt.Errorf("surface: line %v: %v", line, strings.TrimPrefix(err.Error(), "surface: "))
+		}
+
+		if previous, ok := lines[k]; ok {
+			return nil, fmt.Errorf("surface: line %v: key %q already bound on line %v", line, name, previous)
+		}
+		lines[k] = line
+
+		if value == "none" {
+			unbound[k] = true
+			continue
+		}
+
+		c, err := parseCommand(value)
+		if err != nil {
+			return nil, fmt.Errorf("surface: line %v: %v", line, strings.TrimPrefix(err.Error(), "surface: "))
+		}
+		keys[k] = c
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	b := &Bindings{Name: base, Keys: make(map[Key]Command), Invert: invert}
+	for k, c := range KeyPresets[base].Keys {
+		if !unbound[k] {
+			b.Keys[k] = c
+		}
+	}
+	for k, c := range keys {
+		b.Keys[k] = c
+	}
+
+	return b, nil
+}
+
+// parseCommand returns the command for its name
+func parseCommand(name string) (Command, error) {
+	for i, n := range commandNames {
+		if n == name {
+			return Command(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("surface: unknown command %q, available: %v or none", name, commandNames[:])
+}
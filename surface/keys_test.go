@@ -0,0 +1,99 @@
+package surface
+
+import (
+	"github.com/nsf/termbox-go"
+	"github.com/pravj/puzzl/locale"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestKeyLabels(t *testing.T) {
+	b := KeyPresets["arrows"]
+
+	tests := []struct {
+		language string
+		command  Command
+		want     []string
+	}{
+		{"en", CommandQuit, []string{"Esc"}},
+		{"en", CommandHint, []string{"H", "h"}},
+		{"en", CommandUp, []string{"↑"}},
+		{"es", CommandQuit, []string{"Esc"}},
+		{"es", CommandUp, []string{"↑"}},
+	}
+
+	for _, test := range tests {
+		if labels := b.KeyLabels(test.command, locale.Lookup(test.language)); !reflect.DeepEqual(labels, test.want) {
+			t.Errorf("%v labels of %v: %q, want %q", test.language, test.command, labels, test.want)
+		}
+	}
+
+	// special keys are named in the player's language
+	space := Key{Key: termbox.KeySpace}
+	if label := space.Label(locale.Lookup("es")); label != "Espacio" {
+		t.Errorf("Spanish label of the space key: %q, want %q", label, "Espacio")
+	}
+	if label := space.String(); label != "Space" {
+		t.Errorf("label of the space key: %q, want %q", label, "Space")
+	}
+}
+
+func TestParseBindings(t *testing.T) {
+	up, esc := Key{Key: termbox.KeyArrowUp}, Key{Key: termbox.KeyEsc}
+
+	tests := []struct {
+		name string
+		text string
+
+		base   string
+		invert bool
+
+		// keys looked at afterwards, unbound when the command is -1
+		keys map[Key]Command
+	}{
+		{"empty", "", "arrows", false, map[Key]Command{up: CommandUp, {Ch: 'h'}: CommandHint}},
+		{"base", "# vim keys\nbase = vim\n\nx = undo", "vim", false, map[Key]Command{{Ch: 'k'}: CommandUp, {Ch: 'x'}: CommandUndo, {Ch: 'h'}: CommandLeft}},
+		{"invert", "invert = true", "arrows", true, map[Key]Command{up: CommandUp}},
+		{"rebound", "h = left\nesc = none\nq = quit", "arrows", false, map[Key]Command{{Ch: 'h'}: CommandLeft, esc: -1, {Ch: 'q'}: CommandQuit}},
+		{"equals key", "= = help", "arrows", false, map[Key]Command{{Ch: '='}: CommandHelp}},
+		{"special key names", "SPACE = hint\nf1 = help", "arrows", false, map[Key]Command{{Key: termbox.KeySpace}: CommandHint, {Key: termbox.KeyF1}: CommandHelp}},
+	}
+
+	for _, test := range tests {
+		b, err := ParseBindings(strings.NewReader(test.text))
+		if err != nil {
+			t.Errorf("%v: %v", test.name, err)
+			continue
+		}
+
+		if b.Name != test.base || b.Invert != test.invert {
+			t.Errorf("%v: base %v, invert %v, want %v and %v", test.name, b.Name, b.Invert, test.base, test.invert)
+		}
+		for k, want := range test.keys {
+			c, ok := b.Keys[k]
+			if want < 0 && ok || want >= 0 && (!ok || c != want) {
+				t.Errorf("%v: %v bound to %v (%v), want %v", test.name, k, c, ok, want)
+			}
+		}
+	}
+
+	errors := []struct {
+		name string
+		text string
+	}{
+		{"unknown base", "base = emacs"},
+		{"unknown key", "ctrl-x = quit"},
+		{"unknown command", "x = jump"},
+		{"invert not a boolean", "invert = yes"},
+		{"no command", "x"},
+		{"key bound twice", "x = hint\nx = undo"},
+		{"key unbound and bound", "x = none\nx = undo"},
+	}
+
+	for _, test := range errors {
+		if _, err := ParseBindings(strings.NewReader(test.text)); err == nil {
+			t.Errorf("%v: no error", test.name)
+		}
+	}
+}
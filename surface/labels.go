@@ -0,0 +1,84 @@
+package surface
+
+import (
+	"fmt"
+	"github.com/mattn/go-runewidth"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Labels holds the text shown on each tile, indexed by tile value
+// The blank tile, value 0, comes first.
+type Labels []string
+
+// LabelSets lists the built-in label sets by name, each for a board of n tiles
+var LabelSets = map[string]func(n int) Labels{
+	"numbers": Numbers,
+	"letters": Letters,
+}
+
+// Numbers returns the tile values as labels, the blank tile showing 0
+func Numbers(n int) Labels {
+	labels := make(Labels, n)
+	for i := range labels {
+		labels[i] = strconv.Itoa(i)
+	}
+
+	return labels
+}
+
+// Letters returns A to Z for the tiles, then AA, AB and so on like spreadsheet columns
+func Letters(n int) Labels {
+	labels := make(Labels, n)
+	for i := 1; i < n; i++ {
+		var name []byte
+		for v := i; v > 0; v = (v - 1) / 26 {
+			name = append([]byte{byte('A' + (v-1)%26)}, name...)
+		}
+		labels[i] = string(name)
+	}
+
+	return labels
+}
+
+// ParseLabels returns the labels of a board of n tiles for a set name,
+// or for a comma separated list of glyphs for the tiles from 1 to n-1
+func ParseLabels(spec string, n int) (Labels, error) {
+	if set, ok := LabelSets[spec]; ok {
+		return set(n), nil
+	}
+
+	glyphs := strings.Split(spec, ",")
+	if len(glyphs) != n-1 {
+		names := make([]string, 0, len(LabelSets))
+		for name := range LabelSets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		return nil, fmt.Errorf("surface: labels %q are neither a set %v nor %v comma separated glyphs", spec, names, n-1)
+	}
+
+	labels := make(Labels, n)
+	for i, g := range glyphs {
+		labels[i+1] = strings.TrimSpace(g)
+		if labels[i+1] == "" {
+			return nil, fmt.Errorf("surface: label of tile %v is empty", i+1)
+		}
+	}
+
+	return labels, nil
+}
+
+// Width returns the terminal columns of the widest label
+func (l Labels) Width() int {
+	var width int
+	for _, label := range l {
+		if w := runewidth.StringWidth(label); w > width {
+			width = w
+		}
+	}
+
+	return width
+}
@@ -0,0 +1,124 @@
+package surface
+
+// Sizes of the fixed parts of the game screen
+const (
+	// columns around a tile label, two of padding and a border on each side
+	cellPadding int = 6
+	cellHeight  int = 3
+
+	// side panel of three sections, text between two walls
+	panelText   int = 12
+	panelWidth  int = panelText + 2
+	panelHeight int = 9
+
+	notificationHeight int = 3
+
+	// lines of the history panel, along with its borders
+	historyLines  int = 8
+	historyHeight int = historyLines + 2
+)
+
+// Rect is an area of the terminal
+type Rect struct {
+	X, Y          int
+	Width, Height int
+}
+
+// Layout places every part of the game screen in a terminal
+//
+// The side panel goes to the right of the board when there is room for it,
+// below the board otherwise. The history panel is left out when it does not
+// fit, and TooSmall tells that even the rest does not.
+type Layout struct {
+	// columns of a board cell, fitting the largest tile label
+	CellWidth int
+
+	Board        Rect
+	Panel        Rect
+	Notification Rect
+
+	// empty when the history panel is hidden or does not fit
+	History Rect
+
+	// the terminal can not hold the game, Width and Height are the least it needs
+	TooSmall      bool
+	Width, Height int
+}
+
+// NewLayout computes the layout of a rows*cols board whose largest tile label
+// has labelWidth characters, in a terminal of width*height cells
+func NewLayout(width, height, rows, cols, labelWidth int, history bool) Layout {
+	cellWidth := labelWidth + cellPadding
+	boardWidth, boardHeight := cols*cellWidth, rows*cellHeight
+
+	var extra []int
+	if history {
+		extra = append(extra, historyHeight)
+	}
+	extra = append(extra, 0)
+
+	for _, historyRows := range extra {
+		for _, side := range []bool{true, false} {
+			l := Layout{CellWidth: cellWidth}
+
+			contentWidth := boardWidth + panelWidth
+			contentHeight := notificationHeight + max(boardHeight, panelHeight) + historyRows
+			if !side {
+				contentWidth = max(boardWidth, panelWidth)
+				contentHeight = notificationHeight + boardHeight + panelHeight + historyRows
+			}
+
+			if contentWidth > width || contentHeight > height {
+				continue
+			}
+
+			// everything is centered in the terminal
+			x, y := (width-contentWidth)/2, (height-contentHeight)/2
+
+			l.Notification = Rect{X: x, Y: y, Width: contentWidth, Height: notificationHeight}
+			y += notificationHeight
+
+			l.Board = Rect{X: x, Y: y, Width: boardWidth, Height: boardHeight}
+			if side {
+				l.Panel = Rect{X: x + boardWidth, Y: y, Width: panelWidth, Height: panelHeight}
+				y += max(boardHeight, panelHeight)
+			} else {
+				l.Board.X = x + (contentWidth-boardWidth)/2
+				l.Panel = Rect{X: x + (contentWidth-panelWidth)/2, Y: y + boardHeight, Width: panelWidth, Height: panelHeight}
+				y += boardHeight + panelHeight
+			}
+
+			if historyRows > 0 {
+				l.History = Rect{X: x, Y: y, Width: contentWidth, Height: historyRows}
+			}
+
+			l.Width, l.Height = contentWidth, contentHeight
+			return l
+		}
+	}
+
+	// the arrangement closest to fitting, the side panel one or the stacked one
+	l := Layout{CellWidth: cellWidth, TooSmall: true}
+	l.Width, l.Height = boardWidth+panelWidth, notificationHeight+max(boardHeight, panelHeight)
+
+	stackedWidth, stackedHeight := max(boardWidth, panelWidth), notificationHeight+boardHeight+panelHeight
+	if shortage(stackedWidth, width)+shortage(stackedHeight, height) < shortage(l.Width, width)+shortage(l.Height, height) {
+		l.Width, l.Height = stackedWidth, stackedHeight
+	}
+
+	return l
+}
+
+// shortage returns how many cells are missing to fit need into have
+func shortage(need, have int) int {
+	return max(0, need-have)
+}
+
+// max returns the larger of two integers
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,58 @@
+package surface
+
+import (
+	"testing"
+)
+
+func TestNewLayout(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		rows, cols    int
+		history       bool
+
+		tooSmall bool
+		side     bool
+		shown    bool
+
+		// columns and lines the layout takes
+		w, h int
+	}{
+		{"wide with history", 100, 30, 3, 3, true, false, true, true, 35, 22},
+		{"wide without history", 100, 30, 3, 3, false, false, true, false, 35, 12},
+		{"too short for history", 100, 20, 3, 3, true, false, true, false, 35, 12},
+		{"narrow with history", 30, 40, 3, 3, true, false, false, true, 21, 31},
+		{"narrow too short for history", 30, 25, 3, 3, true, false, false, false, 21, 21},
+		{"exactly fitting", 35, 22, 3, 3, true, false, true, true, 35, 22},
+		{"too small, closer to the side panel", 30, 15, 3, 3, true, true, false, false, 35, 12},
+		{"too small, closer to stacking", 20, 30, 3, 3, true, true, false, false, 21, 21},
+		{"larger board", 80, 30, 4, 4, true, false, true, true, 46, 25},
+	}
+
+	for _, test := range tests {
+		// tiles up to 8 take one column, up to 15 two
+		l := NewLayout(test.width, test.height, test.rows, test.cols, 1+test.rows/4, test.history)
+
+		if l.TooSmall != test.tooSmall || l.Width != test.w || l.Height != test.h {
+			t.Errorf("%v: too small %v, %vx%v, want %v, %vx%v", test.name, l.TooSmall, l.Width, l.Height, test.tooSmall, test.w, test.h)
+			continue
+		}
+		if l.TooSmall {
+			continue
+		}
+
+		if side := l.Panel.X >= l.Board.X+l.Board.Width; side != test.side {
+			t.Errorf("%v: panel beside the board %v, want %v", test.name, side, test.side)
+		}
+		if shown := l.History != (Rect{}); shown != test.shown {
+			t.Errorf("%v: history shown %v, want %v", test.name, shown, test.shown)
+		}
+
+		// every part lies within the terminal
+		for _, r := range []Rect{l.Board, l.Panel, l.Notification, l.History} {
+			if r.X < 0 || r.Y < 0 || r.X+r.Width > test.width || r.Y+r.Height > test.height {
+				t.Errorf("%v: %+v out of a %vx%v terminal", test.name, r, test.width, test.height)
+			}
+		}
+	}
+}
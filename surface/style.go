@@ -0,0 +1,62 @@
+package surface
+
+import (
+	"github.com/nsf/termbox-go"
+	"github.com/pravj/puzzl/notification"
+	"github.com/pravj/puzzl/theme"
+)
+
+// outputModes holds the termbox output mode for each color mode
+var outputModes = [...]termbox.OutputMode{
+	theme.Mode16:        termbox.OutputNormal,
+	theme.Mode256:       termbox.Output256,
+	theme.ModeTrueColor: termbox.OutputRGB,
+}
+
+// attribute returns the termbox attribute of a color in the surface's color mode
+func (s *Surface) attribute(c theme.Color) termbox.Attribute {
+	if c == (theme.Color{}) {
+		return termbox.ColorDefault
+	}
+
+	switch {
+	case s.colors == theme.ModeTrueColor:
+		return termbox.RGBToAttribute(c.Value())
+	case s.colors == theme.Mode256 && c.Exact:
+		// termbox counts the palette from 1, leaving 0 for the default color
+		return termbox.Attribute(c.Index256() + 1)
+	}
+
+	return termbox.Attribute(c.Basic)
+}
+
+// style returns the foreground and background attributes of a style
+func (s *Surface) style(st theme.Style) (termbox.Attribute, termbox.Attribute) {
+	fg, bg := s.attribute(st.Fg), s.attribute(st.Bg)
+
+	if st.Bold {
+		fg |= termbox.AttrBold
+	}
+	if st.Underline {
+		fg |= termbox.AttrUnderline
+	}
+	if st.Reverse {
+		fg |= termbox.AttrReverse
+	}
+
+	return fg, bg
+}
+
+// severityStyle returns the style of the current notification for each event severity
+func (s *Surface) severityStyle(severity notification.Severity) theme.Style {
+	switch severity {
+	case notification.Success:
+		return s.theme.Success
+	case notification.Warning:
+		return s.theme.Warning
+	case notification.Error:
+		return s.theme.Error
+	}
+
+	return s.theme.Info
+}
@@ -0,0 +1,38 @@
+package surface
+
+import (
+	"github.com/nsf/termbox-go"
+	"github.com/pravj/puzzl/theme"
+	"testing"
+)
+
+func TestAttribute(t *testing.T) {
+	blue, _ := theme.ParseColor("#0072b2/blue")
+
+	tests := []struct {
+		color theme.Color
+		mode  theme.Mode
+		want  termbox.Attribute
+	}{
+		{theme.Color{}, theme.Mode16, termbox.ColorDefault},
+		{theme.Color{}, theme.Mode256, termbox.ColorDefault},
+		{theme.Color{}, theme.ModeTrueColor, termbox.ColorDefault},
+
+		// basic colors keep their index unless the terminal takes exact ones
+		{theme.Color{Basic: theme.Red}, theme.Mode16, termbox.ColorRed},
+		{theme.Color{Basic: theme.Red}, theme.Mode256, termbox.ColorRed},
+		{theme.Color{Basic: theme.Red}, theme.ModeTrueColor, termbox.RGBToAttribute(205, 0, 0)},
+
+		// exact colors fall back to their basic one, or the closest of the palette
+		{blue, theme.Mode16, termbox.ColorBlue},
+		{blue, theme.Mode256, termbox.Attribute(25 + 1)},
+		{blue, theme.ModeTrueColor, termbox.RGBToAttribute(0x00, 0x72, 0xb2)},
+	}
+
+	for _, test := range tests {
+		s := &Surface{colors: test.mode}
+		if a := s.attribute(test.color); a != test.want {
+			t.Errorf("%+v in %v colors: attribute %v, want %v", test.color, test.mode, a, test.want)
+		}
+	}
+}
@@ -2,15 +2,17 @@
 package surface
 
 import (
-	"container/list"
 	"fmt"
+	"github.com/mattn/go-runewidth"
 	"github.com/nsf/termbox-go"
 	"github.com/pravj/puzzl/board"
+	"github.com/pravj/puzzl/game"
+	"github.com/pravj/puzzl/locale"
 	"github.com/pravj/puzzl/notification"
-	"github.com/pravj/puzzl/score"
-	"github.com/pravj/puzzl/solver"
-	"strconv"
-	"unicode/utf8"
+	"github.com/pravj/puzzl/theme"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
 // rune type Box-drawing characters
@@ -27,395 +29,478 @@ const (
 	blank rune = ' '
 )
 
+// Notification widget and history panel settings
+const (
+	// how often the screen is drawn again, for scrolling and expiring notifications
+	refreshInterval time.Duration = 200 * time.Millisecond
+
+	// time to scroll a long notification by one character
+	scrollStep time.Duration = 250 * time.Millisecond
+)
+
 // Surface represents surface type struct
 // It contains methods for general game terminal interface manipulation
 type Surface struct {
-	gameBoard  *board.Board
-	gameSolver *solver.Solver
+	// rules and progress of the game, the surface only renders it
+	game *game.Game
+
+	// notifications to show, used by the game's owner goroutine only
+	queue *notification.Queue
+
+	// whether the history panel and the help overlay are shown, toggled by the input goroutine
+	showHistory int32
+	showHelp    int32
+
+	// messages in the language of the player
+	catalog *locale.Catalog
+
+	// text shown on each tile
+	labels Labels
 
-	currentBoard *list.Element
-	solved       bool
+	// styles of the screen parts and the colors the terminal shows
+	theme  *theme.Theme
+	colors theme.Mode
 
-	scorer        *score.Score
-	solvableMoves int
+	// command of each key
+	keys *Bindings
+}
+
+// Options tells how the game is shown, zero values pick the defaults
+type Options struct {
+	// messages in the language of the player, English by default
+	Catalog *locale.Catalog
 
-	Message string
+	// text shown on each tile, their numbers by default
+	Labels Labels
 
-	Notifier          *notification.Notification
-	NotificationColor termbox.Attribute
+	// styles of the screen parts, the classic theme by default
+	Theme *theme.Theme
 
-	channelClosed bool
+	// colors the terminal shows, only the basic ones by default
+	Colors theme.Mode
 
-	hintCount int
+	// command of each key, the arrows preset by default
+	Keys *Bindings
 }
 
-// New returns pointer to a new Surface instance
-func New(b *board.Board, s *solver.Solver, n *notification.Notification) *Surface {
-	scorer := score.New()
-	sf := &Surface{gameBoard: b, gameSolver: s, scorer: scorer, Message: notification.WelcomeMessage, Notifier: n, NotificationColor: termbox.ColorCyan, hintCount: 3}
+// New returns pointer to a new Surface instance playing a game
+// until the player quits, the plan for the starting board arrives over plans
+// and notices from outside of the game, if any, over notices
+func New(g *game.Game, plans <-chan game.Plan, notices <-chan notification.Event, opts Options) *Surface {
+	if opts.Catalog == nil {
+		opts.Catalog = locale.Lookup(locale.DefaultLanguage)
+	}
+	if opts.Labels == nil {
+		opts.Labels = Numbers(board.SIZE * board.SIZE)
+	}
+	if opts.Theme == nil {
+		opts.Theme = theme.Themes[theme.DefaultTheme]
+	}
+	if opts.Keys == nil {
+		opts.Keys = KeyPresets[DefaultBindings]
+	}
+
+	sf := &Surface{game: g, queue: notification.NewQueue(notification.DefaultDuration, notification.DefaultHistory)}
+	sf.catalog, sf.labels, sf.theme, sf.colors, sf.keys = opts.Catalog, opts.Labels, opts.Theme, opts.Colors, opts.Keys
+	sf.queue.Push(notification.NewEvent(notification.Welcome, notification.WelcomeMessage))
 
-	sf.initiate()
+	sf.initiate(plans, notices)
 
 	return sf
 }
 
-// Draws a cell(square) structure on terminal
-// That consists the digit from the game board
-func (s *Surface) drawCell(x, y int, ch rune) {
-	// Red color for blank cell and Blue for others
-	var bgColor termbox.Attribute
-	if ch == '0' {
-		bgColor = termbox.ColorRed
-	} else {
-		bgColor = termbox.ColorBlue
-	}
-
-	termbox.SetCell(x, y, cornerUL, termbox.ColorDefault, termbox.ColorCyan)
-	termbox.SetCell(x+1, y, hDash, termbox.ColorDefault, termbox.ColorCyan)
-	termbox.SetCell(x+2, y, hDash, termbox.ColorDefault, termbox.ColorCyan)
-	termbox.SetCell(x+3, y, hDash, termbox.ColorDefault, termbox.ColorCyan)
-	termbox.SetCell(x+4, y, hDash, termbox.ColorDefault, termbox.ColorCyan)
-	termbox.SetCell(x+5, y, hDash, termbox.ColorDefault, termbox.ColorCyan)
-	termbox.SetCell(x+6, y, cornerUR, termbox.ColorDefault, termbox.ColorCyan)
-
-	termbox.SetCell(x, y+1, vDash, termbox.ColorDefault, termbox.ColorCyan)
-	termbox.SetCell(x+1, y+1, blank, termbox.ColorDefault, bgColor)
-	termbox.SetCell(x+2, y+1, blank, termbox.ColorDefault, bgColor)
-	termbox.SetCell(x+3, y+1, ch, termbox.ColorDefault, bgColor)
-	termbox.SetCell(x+4, y+1, blank, termbox.ColorDefault, bgColor)
-	termbox.SetCell(x+5, y+1, blank, termbox.ColorDefault, bgColor)
-	termbox.SetCell(x+6, y+1, vDash, termbox.ColorDefault, termbox.ColorCyan)
-
-	termbox.SetCell(x, y+2, cornerLL, termbox.ColorDefault, termbox.ColorCyan)
-	termbox.SetCell(x+1, y+2, hDash, termbox.ColorDefault, termbox.ColorCyan)
-	termbox.SetCell(x+2, y+2, hDash, termbox.ColorDefault, termbox.ColorCyan)
-	termbox.SetCell(x+3, y+2, hDash, termbox.ColorDefault, termbox.ColorCyan)
-	termbox.SetCell(x+4, y+2, hDash, termbox.ColorDefault, termbox.ColorCyan)
-	termbox.SetCell(x+5, y+2, hDash, termbox.ColorDefault, termbox.ColorCyan)
-	termbox.SetCell(x+6, y+2, cornerLR, termbox.ColorDefault, termbox.ColorCyan)
+// text returns the message of an event in the player's language
+func (s *Surface) text(e notification.Event) string {
+	return s.catalog.Text(e.Message, e.Args...)
 }
 
-// Draws a vertical wall to separate sections
-func (s *Surface) drawWall(x, y int, isLeft bool) {
-	for i := 1; i < 8; i++ {
-		termbox.SetCell(x+21, y+i, vDash, termbox.ColorDefault, termbox.ColorCyan)
+// banner returns the translated title of a section, cut or padded to the panel's columns
+func (s *Surface) banner(key string) []rune {
+	chars := []rune(s.catalog.Text(key))
+	if len(chars) > panelText {
+		chars = chars[:panelText]
 	}
 
-	if isLeft {
-		termbox.SetCell(x+21, y+8, cornerLL, termbox.ColorDefault, termbox.ColorCyan)
-	} else {
-		termbox.SetCell(x+21, y+8, cornerLR, termbox.ColorDefault, termbox.ColorCyan)
+	for len(chars) < panelText {
+		chars = append(chars, ' ')
 	}
+
+	return chars
 }
 
-// Draws game's scoring section
-func (s *Surface) drawScore(x, y int) {
-	// fills the blank space of two ticks
-	termbox.SetCell(x+21, y, blank, termbox.ColorDefault, termbox.ColorCyan)
-	termbox.SetCell(x+34, y, blank, termbox.ColorDefault, termbox.ColorCyan)
+// Draws a cell(square) structure of a given width on terminal
+// That consists the label of a tile from the game board, centered
+func (s *Surface) drawCell(x, y, width, value int) {
+	// the blank cell stands out from the others
+	tile := s.theme.Tile
+	if value == 0 {
+		tile = s.theme.Blank
+	}
+
+	fg, bg := s.style(tile)
+	borderFg, borderBg := s.style(s.theme.Border)
+
+	termbox.SetCell(x, y, cornerUL, borderFg, borderBg)
+	termbox.SetCell(x+width-1, y, cornerUR, borderFg, borderBg)
+	termbox.SetCell(x, y+1, vDash, borderFg, borderBg)
+	termbox.SetCell(x+width-1, y+1, vDash, borderFg, borderBg)
+	termbox.SetCell(x, y+2, cornerLL, borderFg, borderBg)
+	termbox.SetCell(x+width-1, y+2, cornerLR, borderFg, borderBg)
 
-	// score banner
-	chars := []rune{'G', 'A', 'M', 'E', ' ', 'S', 'C', 'O', 'R', 'E', ' ', ' '}
-	for i := 0; i < 12; i++ {
-		termbox.SetCell(x+22+i, y, chars[i], termbox.ColorDefault, termbox.ColorYellow)
+	for i := 1; i < width-1; i++ {
+		termbox.SetCell(x+i, y, hDash, borderFg, borderBg)
+		termbox.SetCell(x+i, y+1, blank, fg, bg)
+		termbox.SetCell(x+i, y+2, hDash, borderFg, borderBg)
 	}
 
-	// score value
-	moves := fmt.Sprintf("%v", s.scorer.Value())
-	length := len(moves)
-	if length > 5 {
-		length = 5
+	label := s.labels[value]
+	left := x + (width-runewidth.StringWidth(label))/2
+
+	// wide glyphs take two columns
+	for _, ch := range label {
+		termbox.SetCell(left, y+1, ch, fg, bg)
+		left += runewidth.RuneWidth(ch)
 	}
+}
 
-	var k int
-	for i := 0; i < length; i++ {
-		r, _ := utf8.DecodeRuneInString(string(moves[i]))
-		termbox.SetCell(x+22+i, y+1, r, termbox.ColorDefault, termbox.ColorMagenta)
-		k = i
+// Draws a vertical wall of the side panel to separate sections
+func (s *Surface) drawWall(x, y int, isLeft bool) {
+	fg, bg := s.style(s.theme.Border)
+
+	// fills the blank space of the tick
+	termbox.SetCell(x, y, blank, fg, bg)
+
+	for i := 1; i < panelHeight-1; i++ {
+		termbox.SetCell(x, y+i, vDash, fg, bg)
 	}
 
-	for j := k + 1; j < 12; j++ {
-		termbox.SetCell(x+22+j, y+1, blank, termbox.ColorDefault, termbox.ColorMagenta)
+	if isLeft {
+		termbox.SetCell(x, y+panelHeight-1, cornerLL, fg, bg)
+	} else {
+		termbox.SetCell(x, y+panelHeight-1, cornerLR, fg, bg)
 	}
 }
 
-// Draws game's player moves section
-func (s *Surface) drawPlayerMoves(x, y int) {
-	// player moves banner
-	chars := []rune{'P', 'L', 'A', 'Y', 'E', 'R', ' ', 'M', 'O', 'V', 'E', 'S'}
-	for i := 0; i < 12; i++ {
-		termbox.SetCell(x+22+i, y+3, chars[i], termbox.ColorDefault, termbox.ColorYellow)
+// Draws a section of the side panel, its banner and value below it
+func (s *Surface) drawSection(r Rect, y int, key, value string) {
+	bannerFg, bannerBg := s.style(s.theme.Banner)
+	valueFg, valueBg := s.style(s.theme.Value)
+
+	chars := s.banner(key)
+	for i := 0; i < panelText; i++ {
+		termbox.SetCell(r.X+1+i, r.Y+y, chars[i], bannerFg, bannerBg)
 	}
 
-	// player moves value
-	moves := fmt.Sprintf("%v", s.scorer.TotalMoves)
-	length := len(moves)
+	runes := []rune(value)
+	for i := 0; i < panelText; i++ {
+		ch := blank
+		if i < len(runes) {
+			ch = runes[i]
+		}
 
-	var k int
-	for i := 0; i < length; i++ {
-		r, _ := utf8.DecodeRuneInString(string(moves[i]))
-		termbox.SetCell(x+22+i, y+4, r, termbox.ColorDefault, termbox.ColorMagenta)
-		k = i
+		termbox.SetCell(r.X+1+i, r.Y+y+1, ch, valueFg, valueBg)
 	}
+}
 
-	for j := k + 1; j < 12; j++ {
-		termbox.SetCell(x+22+j, y+4, blank, termbox.ColorDefault, termbox.ColorMagenta)
+// Draws game's scoring section
+func (s *Surface) drawScore(r Rect, state game.State) {
+	// score value
+	moves := fmt.Sprintf("%v", state.Score)
+	if len(moves) > 5 {
+		moves = moves[:5]
 	}
+
+	s.drawSection(r, 0, "game-score", moves)
+}
+
+// Draws game's player moves section
+func (s *Surface) drawPlayerMoves(r Rect, state game.State) {
+	s.drawSection(r, 3, "player-moves", fmt.Sprintf("%v", state.TotalMoves))
 }
 
 // Draws game's solver moves section
-func (s *Surface) drawSolverMoves(x, y int) {
-	// solver moves banner
-	chars := []rune{'S', 'O', 'L', 'V', 'A', 'B', 'L', 'E', ' ', 'I', 'N', ' '}
-	for i := 0; i < 12; i++ {
-		termbox.SetCell(x+22+i, y+6, chars[i], termbox.ColorDefault, termbox.ColorYellow)
+func (s *Surface) drawSolverMoves(r Rect, state game.State) {
+	// solver moves value, along with the difficulty once rated
+	moves := fmt.Sprintf("%v", state.SolvableMoves)
+	if state.Rating.Level != 0 {
+		moves = fmt.Sprintf("%v (%v)", state.SolvableMoves, s.catalog.Text(state.Rating.Level.String()))
 	}
 
-	// solver moves value
-	moves := fmt.Sprintf("%v", s.solvableMoves)
-	length := len(moves)
+	s.drawSection(r, 6, "solvable-in", moves)
+}
 
-	var k int
-	for i := 0; i < length; i++ {
-		r, _ := utf8.DecodeRuneInString(string(moves[i]))
-		termbox.SetCell(x+22+i, y+7, r, termbox.ColorDefault, termbox.ColorMagenta)
-		k = i
-	}
+// Draws horizontal partitioning below a section of the side panel
+func (s *Surface) drawPartition(r Rect, y int) {
+	fg, bg := s.style(s.theme.Border)
 
-	for j := k + 1; j < 12; j++ {
-		termbox.SetCell(x+22+j, y+7, blank, termbox.ColorDefault, termbox.ColorMagenta)
+	for i := 0; i < panelText; i++ {
+		termbox.SetCell(r.X+1+i, r.Y+y, hDash, fg, bg)
 	}
 }
 
-// Draws horizontal partitioning between different sections
-func (s *Surface) drawPartition(x, y int) {
-	for i := 0; i < 12; i++ {
-		termbox.SetCell(x+22+i, y+8, hDash, termbox.ColorDefault, termbox.ColorCyan)
-	}
-}
+// Draws the current notification in real time, scrolling it when it is too long
+func (s *Surface) drawNotification(r Rect, now time.Time) {
+	// characters inside the notification widget
+	width := r.Width - 2
+	x, y, right := r.X, r.Y, r.X+r.Width-1
 
-// Draws notifications in real time
-func (s *Surface) drawNotification(x, y int, message string) {
 	// notification widget boundary
-	termbox.SetCell(x, y-3, cornerUL, termbox.ColorDefault, termbox.ColorBlue)
-	termbox.SetCell(x, y-2, vDash, termbox.ColorDefault, termbox.ColorBlue)
-	termbox.SetCell(x, y-1, cornerLL, termbox.ColorDefault, termbox.ColorBlue)
-
-	for i := 0; i < 33; i++ {
-		termbox.SetCell(x+1+i, y-3, hDash, termbox.ColorDefault, termbox.ColorBlue)
-		termbox.SetCell(x+1+i, y-1, hDash, termbox.ColorDefault, termbox.ColorBlue)
+	frameFg, frameBg := s.style(s.theme.Frame)
+	termbox.SetCell(x, y, cornerUL, frameFg, frameBg)
+	termbox.SetCell(x, y+1, vDash, frameFg, frameBg)
+	termbox.SetCell(x, y+2, cornerLL, frameFg, frameBg)
+
+	for i := 0; i < width; i++ {
+		termbox.SetCell(x+1+i, y, hDash, frameFg, frameBg)
+		termbox.SetCell(x+1+i, y+2, hDash, frameFg, frameBg)
 	}
 
-	termbox.SetCell(x+34, y-3, cornerUR, termbox.ColorDefault, termbox.ColorBlue)
-	termbox.SetCell(x+34, y-2, vDash, termbox.ColorDefault, termbox.ColorBlue)
-	termbox.SetCell(x+34, y-1, cornerLR, termbox.ColorDefault, termbox.ColorBlue)
+	termbox.SetCell(right, y, cornerUR, frameFg, frameBg)
+	termbox.SetCell(right, y+1, vDash, frameFg, frameBg)
+	termbox.SetCell(right, y+2, cornerLR, frameFg, frameBg)
 
-	// notification message value
-	var k int
-	for i := 0; i < len(message); i++ {
-		r, _ := utf8.DecodeRuneInString(string(message[i]))
-		termbox.SetCell(x+1+i, y-2, r, termbox.ColorDefault, s.NotificationColor)
-		k = i
+	// notification message value, nothing once it has expired
+	style := s.theme.Text
+	var message []rune
+
+	if e, ok := s.queue.Current(now); ok {
+		style = s.severityStyle(e.Severity)
+		message = []rune(notification.Scroll(s.text(e), width, now.Sub(e.Time), scrollStep))
 	}
 
-	for j := k + 2; j < 34; j++ {
-		termbox.SetCell(x+j, y-2, blank, termbox.ColorDefault, s.NotificationColor)
+	fg, bg := s.style(style)
+	for i := 0; i < width; i++ {
+		ch := blank
+		if i < len(message) {
+			ch = message[i]
+		}
+
+		termbox.SetCell(x+1+i, y+1, ch, fg, bg)
 	}
 }
 
-// Combines all the sections and draw the entire game board accordingly
-func (s *Surface) drawBoard() {
-	w, h := termbox.Size()
-	const coldef = termbox.ColorDefault
-
-	midy := h/2 - 5
-	midx := w/2 - 15
+// Draws the latest notifications with their time below the game board
+func (s *Surface) drawHistory(r Rect) {
+	const timeWidth = 9
+	lineCount := r.Height - 2
+	width := r.Width - 2 - timeWidth
+
+	// lines of the newest notifications that fit, newest at the bottom
+	var lines [][2]string
+	history := s.queue.History(lineCount)
+	for i := len(history) - 1; i >= 0 && len(lines) < lineCount; i-- {
+		wrapped := notification.Wrap(s.text(history[i]), width)
+
+		for j := len(wrapped) - 1; j >= 0 && len(lines) < lineCount; j-- {
+			stamp := ""
+			if j == 0 {
+				stamp = history[i].Time.Format("15:04:05")
+			}
 
-	for i := 0; i < 3; i++ {
-		for j := 0; j < 3; j++ {
-			r, _ := utf8.DecodeRuneInString(strconv.Itoa(s.gameBoard.Rows[i].Tiles[j].Value))
-			s.drawCell(midx+7*j, midy+3*i, r)
+			lines = append([][2]string{{stamp, wrapped[j]}}, lines...)
 		}
 	}
 
-	s.drawWall(midx, midy, true)
-	s.drawWall(midx+13, midy, false)
-
-	s.drawScore(midx, midy)
-	s.drawPlayerMoves(midx, midy)
-	s.drawSolverMoves(midx, midy)
+	// history panel boundary
+	frameFg, frameBg := s.style(s.theme.Frame)
+	accentFg, accentBg := s.style(s.theme.Accent)
+	textFg, textBg := s.style(s.theme.Text)
+
+	x, y := r.X, r.Y
+	right, bottom := r.X+r.Width-1, r.Y+r.Height-1
+	termbox.SetCell(x, y, cornerUL, frameFg, frameBg)
+	termbox.SetCell(right, y, cornerUR, frameFg, frameBg)
+	termbox.SetCell(x, bottom, cornerLL, frameFg, frameBg)
+	termbox.SetCell(right, bottom, cornerLR, frameFg, frameBg)
+
+	for i := 1; i < r.Width-1; i++ {
+		termbox.SetCell(x+i, y, hDash, frameFg, frameBg)
+		termbox.SetCell(x+i, bottom, hDash, frameFg, frameBg)
+	}
 
-	s.drawPartition(midx, midy-3)
-	s.drawPartition(midx, midy-6)
-	s.drawPartition(midx, midy)
+	for i := 0; i < lineCount; i++ {
+		termbox.SetCell(x, y+1+i, vDash, frameFg, frameBg)
+		termbox.SetCell(right, y+1+i, vDash, frameFg, frameBg)
 
-	s.drawNotification(midx, midy, s.Message)
+		if i >= len(lines) {
+			continue
+		}
 
-	termbox.Flush()
+		for j, ch := range lines[i][0] {
+			termbox.SetCell(x+1+j, y+1+i, ch, accentFg, accentBg)
+		}
+		for j, ch := range []rune(lines[i][1]) {
+			termbox.SetCell(x+1+timeWidth+j, y+1+i, ch, textFg, textBg)
+		}
+	}
 }
 
-// decide the game actions according to the game state
-func (s *Surface) decideAction(dx, dy int) {
-	if !s.channelClosed {
-		s.moveTile(dx, dy)
-	} else {
-		// NOTIFICATION COLOR -> RED
-		s.NotificationColor = termbox.ColorRed
+// Draws a notice in place of the game when the terminal can not hold it
+func (s *Surface) drawTooSmall(l Layout, w, h int) {
+	if w <= 0 || h <= 0 {
+		return
+	}
 
-		s.Message = notification.QuitMessage
-		s.drawBoard()
+	// the message is wrapped to the terminal, as much of it as fits
+	lines := notification.Wrap(s.catalog.Text("too-small", l.Width, l.Height), w)
+	if len(lines) > h {
+		lines = lines[:h]
 	}
-}
 
-// move a tile in a given direction
-func (s *Surface) moveTile(dx, dy int) {
-	newX, newY := s.gameBoard.BlankRow+dx, s.gameBoard.BlankCol+dy
-
-	// a possible move
-	if ((newX >= 0) && (newX <= 2)) && ((newY >= 0) && (newY <= 2)) {
-		// game has been solved by the solver
-		if s.gameSolver.Solved {
-			if !s.solved {
-				s.solved = true
-				s.currentBoard = s.gameSolver.Path.Front()
-				s.solvableMoves = s.gameSolver.Path.Len()
-			}
+	fg, bg := s.style(s.theme.Accent)
 
-			// solved by solver
-			s.gameBoard.Move(newX, newY)
+	y := (h - len(lines)) / 2
+	for i, line := range lines {
+		runes := []rune(line)
+		x := (w - len(runes)) / 2
 
-			// updates the total game moves played till now
-			s.scorer.TotalMoves++
+		for j, ch := range runes {
+			termbox.SetCell(x+j, y+i, ch, fg, bg)
+		}
+	}
+}
 
-			// right move by player
-			if s.currentBoard.Value.(board.Board) == *s.gameBoard {
-				if s.currentBoard.Next() != nil {
-					s.currentBoard = s.currentBoard.Next()
-				}
+// Draws the active key bindings in a box over the middle of the screen
+func (s *Surface) drawHelp(w, h int) {
+	// a line for each command having keys, its description and then the keys
+	var lines [][2]string
+	for c := CommandUp; c <= CommandQuit; c++ {
+		labels := s.keys.KeyLabels(c, s.catalog)
+		if len(labels) == 0 {
+			continue
+		}
 
-				// NOTIFICATION -> RIGHT MOVE
-				s.Message = notification.RightMoveMessage
+		lines = append(lines, [2]string{s.describe(c), strings.Join(labels, " ")})
+	}
 
-				// NOTIFICATION COLOR -> GREEN
-				s.NotificationColor = termbox.ColorGreen
+	title := s.catalog.Text("help-title")
+	descriptionWidth, width := 0, runewidth.StringWidth(title)
+	for _, line := range lines {
+		if lw := runewidth.StringWidth(line[0]); lw > descriptionWidth {
+			descriptionWidth = lw
+		}
+	}
+	for _, line := range lines {
+		if lw := descriptionWidth + 2 + runewidth.StringWidth(line[1]); lw > width {
+			width = lw
+		}
+	}
 
-				// updates the solvable moves count
-				s.solvableMoves--
+	// box of the lines along with a border and a space on each side
+	boxWidth, boxHeight := width+4, len(lines)+4
+	x, y := (w-boxWidth)/2, (h-boxHeight)/2
+	right, bottom := x+boxWidth-1, y+boxHeight-1
 
-				// increase the player's total
-				s.scorer.PlayerTotal++
-			} else {
-				// wrong move by player
-				s.gameSolver = solver.New(s.gameBoard)
+	frameFg, frameBg := s.style(s.theme.Frame)
+	accentFg, accentBg := s.style(s.theme.Accent)
+	textFg, textBg := s.style(s.theme.Text)
 
-				go func() {
-					s.gameSolver.Solve()
-					s.solved = false
-					s.solvableMoves = s.gameSolver.Path.Len()
+	for i := y; i <= bottom; i++ {
+		for j := x; j <= right; j++ {
+			termbox.SetCell(j, i, blank, textFg, textBg)
+		}
+	}
 
-					// NOTIFICATION COLOR -> GREEN
-					s.NotificationColor = termbox.ColorGreen
+	termbox.SetCell(x, y, cornerUL, frameFg, frameBg)
+	termbox.SetCell(right, y, cornerUR, frameFg, frameBg)
+	termbox.SetCell(x, bottom, cornerLL, frameFg, frameBg)
+	termbox.SetCell(right, bottom, cornerLR, frameFg, frameBg)
 
-					s.Notifier.Tunnel <- notification.ReadyToPlayMessage
-				}()
+	for j := x + 1; j < right; j++ {
+		termbox.SetCell(j, y, hDash, frameFg, frameBg)
+		termbox.SetCell(j, bottom, hDash, frameFg, frameBg)
+	}
+	for i := y + 1; i < bottom; i++ {
+		termbox.SetCell(x, i, vDash, frameFg, frameBg)
+		termbox.SetCell(right, i, vDash, frameFg, frameBg)
+	}
 
-				// NOTIFICATION -> WRONG MOVE
-				s.Message = notification.WrongMoveMessage
+	s.drawText(x+2, y+1, title, accentFg, accentBg)
+	for i, line := range lines {
+		s.drawText(x+2, y+3+i, line[0], textFg, textBg)
+		s.drawText(x+4+descriptionWidth, y+3+i, line[1], accentFg, accentBg)
+	}
+}
 
-				// NOTIFICATION COLOR -> RED
-				s.NotificationColor = termbox.ColorRed
+// describe returns what a command does in the player's language
+func (s *Surface) describe(c Command) string {
+	switch c {
+	case CommandUp, CommandDown, CommandLeft, CommandRight:
+		key := "help-move-blank"
+		if s.keys.Invert {
+			key = "help-move-tile"
+		}
 
-				// decrease the player's total
-				s.scorer.PlayerTotal--
-			}
+		return s.catalog.Text(key, locale.Key(c.String()))
+	}
 
-			// solved by player too. Bingo.
-			// NOTIFICATION -> GAME COMPLETE
-			if *s.gameBoard == s.gameSolver.Goal {
-				s.Message = notification.GameCompleteMessage
+	return s.catalog.Text("help-" + c.String())
+}
 
-				// NOTIFICATION COLOR -> CYAN
-				s.NotificationColor = termbox.ColorCyan
+// Draws a text from a position onwards, wide characters taking two columns
+func (s *Surface) drawText(x, y int, text string, fg, bg termbox.Attribute) {
+	for _, ch := range text {
+		termbox.SetCell(x, y, ch, fg, bg)
+		x += runewidth.RuneWidth(ch)
+	}
+}
 
-				// update game status, close notification channel
-				close(s.Notifier.Tunnel)
-				s.channelClosed = true
-			}
+// Combines all the sections and draw the entire game board accordingly
+// The layout is computed again on every draw, following the terminal size.
+func (s *Surface) drawBoard(state game.State) {
+	w, h := termbox.Size()
+	const coldef = termbox.ColorDefault
 
-			// draws the game board, will have the updated total moves also
-			s.drawBoard()
+	termbox.Clear(coldef, coldef)
 
-		} else {
-			// not yet solved by solver : NOTIFICATION -> WAIT
-			s.Message = notification.WaitMessage
-			s.drawBoard()
+	l := NewLayout(w, h, board.SIZE, board.SIZE, s.labels.Width(), atomic.LoadInt32(&s.showHistory) == 1)
+	if l.TooSmall {
+		s.drawTooSmall(l, w, h)
+		termbox.Flush()
+		return
+	}
 
-			// NOTIFICATION COLOR -> Yellow
-			s.NotificationColor = termbox.ColorYellow
+	for i := 0; i < board.SIZE; i++ {
+		for j := 0; j < board.SIZE; j++ {
+			s.drawCell(l.Board.X+l.CellWidth*j, l.Board.Y+cellHeight*i, l.CellWidth, state.Board.Rows[i].Tiles[j].Value)
 		}
-	} else {
-		// an impossible move : NOTIFICATION -> CAN NOT MOVE THERE
-		s.Message = notification.ImpossibleMoveMessage
-
-		// NOTIFICATION COLOR -> RED
-		s.NotificationColor = termbox.ColorRed
-
-		s.drawBoard()
 	}
-}
 
-// shows hints when asked, there is a limit for hints though
-func (s *Surface) showHint() {
-	if s.gameSolver.Solved && s.hintCount > 0 {
-		present := s.gameBoard
-		presentRow, presentCol := present.BlankRow, present.BlankCol
-
-		future := s.currentBoard.Value.(board.Board)
-		futureRow, futureCol := future.BlankRow, future.BlankCol
-
-		var direction string
-
-		if futureRow > presentRow {
-			direction = "down"
-		} else if futureRow < presentRow {
-			direction = "up"
-		} else if futureCol > presentCol {
-			direction = "right"
-		} else if futureCol < presentCol {
-			direction = "left"
-		}
+	s.drawWall(l.Panel.X, l.Panel.Y, true)
+	s.drawWall(l.Panel.X+panelWidth-1, l.Panel.Y, false)
 
-		s.NotificationColor = termbox.ColorCyan
-		s.hintCount--
-		s.Message = fmt.Sprintf("Hint #%v - move %v side", 3-s.hintCount, direction)
-	} else if s.gameSolver.Solved && s.hintCount <= 0 {
-		s.NotificationColor = termbox.ColorRed
-		s.Message = "No more hints my friend."
-	} else {
-		s.NotificationColor = termbox.ColorYellow
-		s.Message = notification.WaitMessage
-	}
+	s.drawScore(l.Panel, state)
+	s.drawPlayerMoves(l.Panel, state)
+	s.drawSolverMoves(l.Panel, state)
 
-	s.drawBoard()
-}
+	s.drawPartition(l.Panel, 2)
+	s.drawPartition(l.Panel, 5)
+	s.drawPartition(l.Panel, 8)
 
-// Initialize all the concurrent processes
-// To monitor user input events and notification communication
-func (s *Surface) initiate() {
+	s.drawNotification(l.Notification, time.Now())
 
-	go func() {
-		for e := range s.Notifier.Tunnel {
-			// updates the solvable moves count for the game
-			// it fixes the issue where game wasn't showing it in the starting
-			s.solvableMoves = s.gameSolver.Path.Len()
+	if l.History.Height > 0 {
+		s.drawHistory(l.History)
+	}
 
-			s.currentBoard = s.gameSolver.Path.Front()
+	if atomic.LoadInt32(&s.showHelp) == 1 {
+		s.drawHelp(w, h)
+	}
 
-			s.Message = e
-			s.drawBoard()
-		}
-	}()
+	termbox.Flush()
+}
 
+// shows the outcome of a player action and draws the updated board
+// it is called by the game's owner goroutine only
+func (s *Surface) render(state game.State, e notification.Event) {
+	s.queue.Push(e)
+	s.drawBoard(state)
+}
+
+// Initialize the terminal and run the game
+// Input events are read on their own goroutine and handed over to the game,
+// which owns all the state and asks the surface to render every change.
+func (s *Surface) initiate(plans <-chan game.Plan, notices <-chan notification.Event) {
 	err := termbox.Init()
 	if err != nil {
 		panic(err)
@@ -423,42 +508,86 @@ func (s *Surface) initiate() {
 	defer termbox.Close()
 
 	termbox.SetInputMode(termbox.InputEsc)
+
+	// falls back to the basic colors where termbox does not take the mode
+	if termbox.SetOutputMode(outputModes[s.colors]) != outputModes[s.colors] {
+		s.colors = theme.Mode16
+	}
 	termbox.HideCursor()
 
-	s.drawBoard()
+	s.drawBoard(s.game.State())
+
+	actions := make(chan game.Action)
+	done := make(chan bool)
+	defer close(done)
 
-GameLoop:
-	for {
-		switch ev := termbox.PollEvent(); ev.Type {
+	// error of the terminal, handed over along with the Quit action
+	var inputErr error
 
-		case termbox.EventKey:
-			switch ev.Key {
-			case termbox.KeyEsc:
-				if !s.channelClosed {
-					close(s.Notifier.Tunnel)
+	go func() {
+		for {
+			var action game.Action
+			ok := true
+
+			switch ev := termbox.PollEvent(); ev.Type {
+			case termbox.EventKey:
+				var command Command
+				command, ok = s.keys.Command(ev)
+
+				// the history panel and the help overlay belong to the surface,
+				// the game is only asked to redraw
+				switch command {
+				case CommandHistory:
+					atomic.StoreInt32(&s.showHistory, 1-atomic.LoadInt32(&s.showHistory))
+					action = game.Refresh
+				case CommandHelp:
+					atomic.StoreInt32(&s.showHelp, 1-atomic.LoadInt32(&s.showHelp))
+					action = game.Refresh
+				default:
+					action = s.keys.Action(command)
 				}
-				break GameLoop
-			case termbox.KeyArrowUp:
-				s.decideAction(-1, 0)
-			case termbox.KeyArrowDown:
-				s.decideAction(1, 0)
-			case termbox.KeyArrowLeft:
-				s.decideAction(0, -1)
-			case termbox.KeyArrowRight:
-				s.decideAction(0, 1)
+			case termbox.EventResize:
+				// the layout follows the new terminal size on the next draw
+				action = game.Refresh
+			case termbox.EventError:
+				inputErr, action = ev.Err, game.Quit
+			default:
+				action = game.Refresh
+			}
+
+			if !ok {
+				continue
 			}
 
-			switch ev.Ch {
-			case 'h':
-				s.showHint()
-			case 'H':
-				s.showHint()
+			select {
+			case actions <- action:
+			case <-done:
+				return
 			}
 
-		case termbox.EventError:
-			panic(ev.Err)
+			if action == game.Quit {
+				return
+			}
 		}
+	}()
+
+	// keeps scrolling and expiring the notifications
+	go func() {
+		ticker := time.NewTicker(refreshInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			select {
+			case actions <- game.Refresh:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	s.game.Run(actions, plans, notices, s.render)
 
-		s.drawBoard()
+	if inputErr != nil {
+		panic(inputErr)
 	}
 }
@@ -0,0 +1,447 @@
+// Package theme describes the colors of the game screen
+//
+// A Theme gives a Style to every part of the screen. Colors are one of the
+// 16 basic terminal colors, optionally along with an exact color shown by
+// terminals of 256 colors or truecolor ones. Themes are built in or loaded
+// from files of "part = style" lines like
+//
+//	base = classic
+//	tile = bold white on #0072b2/blue
+//	error = reverse
+//
+// where base picks the theme the others change, and a style is optional
+// attributes, a foreground color and "on" a background color.
+package theme
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultTheme is the theme used unless another one is asked for
+const DefaultTheme string = "classic"
+
+// Basic is one of the colors every color terminal has, in their ANSI order
+// Default is the terminal's own color.
+type Basic int
+
+// Basic colors
+const (
+	Default Basic = iota
+	Black
+	Red
+	Green
+	Yellow
+	Blue
+	Magenta
+	Cyan
+	White
+	BrightBlack
+	BrightRed
+	BrightGreen
+	BrightYellow
+	BrightBlue
+	BrightMagenta
+	BrightCyan
+	BrightWhite
+)
+
+// basicNames holds the name of each basic color
+var basicNames = [...]string{"default", "black", "red", "green", "yellow", "blue", "magenta", "cyan", "white",
+	"bright-black", "bright-red", "bright-green", "bright-yellow", "bright-blue", "bright-magenta", "bright-cyan", "bright-white"}
+
+// basicRGB holds the usual xterm values of the basic colors, starting with black
+var basicRGB = [...][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0}, {0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0}, {92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// String returns the name of a basic color
+func (b Basic) String() string {
+	if b < Default || b > BrightWhite {
+		return fmt.Sprintf("Basic(%d)", int(b))
+	}
+
+	return basicNames[b]
+}
+
+// Color is a basic color, along with an exact one for terminals having more colors
+type Color struct {
+	Basic Basic
+
+	// exact color, used instead of the basic one by 256 color and truecolor terminals
+	RGB   [3]uint8
+	Exact bool
+}
+
+// RGB returns an exact color, along with the closest basic color for other terminals
+func RGB(r, g, b uint8) Color {
+	return Color{Basic: nearestBasic(r, g, b), RGB: [3]uint8{r, g, b}, Exact: true}
+}
+
+// Value returns the red, green and blue values of a color other than Default
+func (c Color) Value() (uint8, uint8, uint8) {
+	if c.Exact {
+		return c.RGB[0], c.RGB[1], c.RGB[2]
+	}
+
+	v := basicRGB[c.Basic-1]
+	return v[0], v[1], v[2]
+}
+
+// Index256 returns the index of a color other than Default in the 256 color palette
+// Basic colors are the first 16, exact ones the closest of the color cube or the grays.
+func (c Color) Index256() int {
+	if !c.Exact {
+		return int(c.Basic) - 1
+	}
+
+	// levels of the 6x6x6 color cube, starting at index 16
+	levels := [...]int{0, 95, 135, 175, 215, 255}
+
+	var cube [3]int
+	for i, v := range c.RGB {
+		for l := range levels {
+			if abs(int(v)-levels[l]) < abs(int(v)-levels[cube[i]]) {
+				cube[i] = l
+			}
+		}
+	}
+	index := 16 + 36*cube[0] + 6*cube[1] + cube[2]
+	cubeDistance := distance(c.RGB, [3]uint8{uint8(levels[cube[0]]), uint8(levels[cube[1]]), uint8(levels[cube[2]])})
+
+	// 24 grays from 8 to 238, starting at index 232
+	average := (int(c.RGB[0]) + int(c.RGB[1]) + int(c.RGB[2])) / 3
+	gray := (average - 3) / 10
+	if gray < 0 {
+		gray = 0
+	} else if gray > 23 {
+		gray = 23
+	}
+	level := uint8(8 + 10*gray)
+
+	if distance(c.RGB, [3]uint8{level, level, level}) < cubeDistance {
+		index = 232 + gray
+	}
+
+	return index
+}
+
+// nearestBasic returns the basic color closest to an exact one
+func nearestBasic(r, g, b uint8) Basic {
+	best := Black
+	for i, v := range basicRGB {
+		if distance([3]uint8{r, g, b}, v) < distance([3]uint8{r, g, b}, basicRGB[best-1]) {
+			best = Basic(i + 1)
+		}
+	}
+
+	return best
+}
+
+// distance returns the squared distance of two colors
+func distance(a, b [3]uint8) int {
+	var d int
+	for i := range a {
+		d += (int(a[i]) - int(b[i])) * (int(a[i]) - int(b[i]))
+	}
+
+	return d
+}
+
+// abs returns the absolute value of an integer
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
+
+// ParseColor returns the color for a basic color name, an exact color like
+// #0072b2 or an exact color along with its basic one like #0072b2/blue
+func ParseColor(text string) (Color, error) {
+	text = strings.ToLower(text)
+
+	if !strings.HasPrefix(text, "#") {
+		for i, name := range basicNames {
+			if name == text {
+				return Color{Basic: Basic(i)}, nil
+			}
+		}
+
+		return Color{}, fmt.Errorf("theme: unknown color %q, available: %v or #rrggbb", text, basicNames[:])
+	}
+
+	hex, fallback, slash := text[1:], "", false
+	if i := strings.Index(hex, "/"); i >= 0 {
+		hex, fallback, slash = hex[:i], hex[i+1:], true
+	}
+
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 6 {
+		return Color{}, fmt.Errorf("theme: malformed color %q, like #0072b2 expected", text)
+	}
+
+	c := RGB(uint8(value>>16), uint8(value>>8), uint8(value))
+	if slash {
+		basic, err := ParseColor(fallback)
+		if err != nil || basic.Exact || basic.Basic == Default {
+			return Color{}, fmt.Errorf("theme: %q is not a basic color for %q", fallback, text)
+		}
+		c.Basic = basic.Basic
+	}
+
+	return c, nil
+}
+
+// Style is how a part of the screen is drawn
+type Style struct {
+	Fg, Bg Color
+
+	Bold      bool
+	Underline bool
+	Reverse   bool
+}
+
+// ParseStyle returns the style for a text like "bold white on blue",
+// attributes come first and every part is optional
+func ParseStyle(text string) (Style, error) {
+	var s Style
+	fields := strings.Fields(strings.ToLower(text))
+
+attributes:
+	for len(fields) > 0 {
+		switch fields[0] {
+		case "bold":
+			s.Bold = true
+		case "underline":
+			s.Underline = true
+		case "reverse":
+			s.Reverse = true
+		default:
+			break attributes
+		}
+		fields = fields[1:]
+	}
+
+	if len(fields) > 0 && fields[0] != "on" {
+		c, err := ParseColor(fields[0])
+		if err != nil {
+			return Style{}, err
+		}
+		s.Fg, fields = c, fields[1:]
+	}
+
+	if len(fields) > 0 && fields[0] == "on" {
+		if len(fields) < 2 {
+			return Style{}, fmt.Errorf("theme: background color missing in %q", text)
+		}
+
+		c, err := ParseColor(fields[1])
+		if err != nil {
+			return Style{}, err
+		}
+		s.Bg, fields = c, fields[2:]
+	}
+
+	if len(fields) > 0 {
+		return Style{}, fmt.Errorf("theme: unexpected %q in style %q", fields[0], text)
+	}
+
+	return s, nil
+}
+
+// Theme holds the style of each part of the game screen
+type Theme struct {
+	Name string
+
+	// borders of the tiles and of the side panel
+	Border Style
+
+	Tile  Style
+	Blank Style
+
+	// titles and values of the side panel sections
+	Banner Style
+	Value  Style
+
+	// borders of the notification widget and of the history panel
+	Frame Style
+
+	// time of notifications in the history, notices about the terminal
+	Accent Style
+
+	// notifications in the history, expired ones
+	Text Style
+
+	// current notification, by severity
+	Info    Style
+	Success Style
+	Warning Style
+	Error   Style
+}
+
+// parts returns the styles of a theme by name, as written in theme files
+func (t *Theme) parts() map[string]*Style {
+	return map[string]*Style{
+		"border":  &t.Border,
+		"tile":    &t.Tile,
+		"blank":   &t.Blank,
+		"banner":  &t.Banner,
+		"value":   &t.Value,
+		"frame":   &t.Frame,
+		"accent":  &t.Accent,
+		"text":    &t.Text,
+		"info":    &t.Info,
+		"success": &t.Success,
+		"warning": &t.Warning,
+		"error":   &t.Error,
+	}
+}
+
+// Themes lists the built-in themes by name
+var Themes = map[string]*Theme{
+	"classic":       classic,
+	"monochrome":    monochrome,
+	"high-contrast": highContrast,
+	"colorblind":    colorblind,
+	"tritan":        tritan,
+}
+
+// Names returns the sorted names of the built-in themes
+func Names() []string {
+	var names []string
+	for n := range Themes {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Open returns the built-in theme of a name, or the theme of a file otherwise
+func Open(name string) (*Theme, error) {
+	if t, ok := Themes[name]; ok {
+		return t, nil
+	}
+
+	file, err := os.Open(name)
+	if os.IsNotExist(err) && !strings.ContainsAny(name, "./") {
+		return nil, fmt.Errorf("theme: unknown theme %q, available: %v or a theme file", name, Names())
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	t, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("%v (%v)", err, name)
+	}
+	t.Name = name
+
+	return t, nil
+}
+
+// Parse reads a theme file, blank lines and lines starting with # are skipped
+// Parts missing from the file keep the style of the base theme, classic by default.
+func Parse(r io.Reader) (*Theme, error) {
+	base := DefaultTheme
+	styles := make(map[string]Style)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		i := strings.Index(text, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("theme: line %v: \"part = style\" expected", line)
+		}
+		key, value := strings.ToLower(strings.TrimSpace(text[:i])), strings.TrimSpace(text[i+1:])
+
+		if key == "base" {
+			if _, ok := Themes[value]; !ok {
+				return nil, fmt.Errorf("theme: line %v: unknown base theme %q, available: %v", line, value, Names())
+			}
+			base = value
+			continue
+		}
+
+		if _, ok := (&Theme{}).parts()[key]; !ok {
+			return nil, fmt.Errorf("theme: line %v: unknown part %q", line, key)
+		}
+
+		s, err := ParseStyle(value)
+		if err != nil {
+			return nil, fmt.Errorf("theme: line %v: %v", line, strings.TrimPrefix(err.Error(), "theme: "))
+		}
+		styles[key] = s
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	t := *Themes[base]
+	parts := t.parts()
+	for key, s := range styles {
+		*parts[key] = s
+	}
+
+	return &t, nil
+}
+
+// Mode is how many colors a terminal shows
+type Mode int
+
+// Color modes, only the basic colors are used by the first one
+const (
+	Mode16 Mode = iota
+	Mode256
+	ModeTrueColor
+)
+
+// modeNames holds the name of each mode
+var modeNames = [...]string{"16", "256", "truecolor"}
+
+// String returns the name of a mode
+func (m Mode) String() string {
+	if m < Mode16 || m > ModeTrueColor {
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+
+	return modeNames[m]
+}
+
+// ParseMode returns the mode for its name
+func ParseMode(name string) (Mode, error) {
+	for i, n := range modeNames {
+		if n == name {
+			return Mode(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("theme: unknown color mode %q, available: %v", name, modeNames[:])
+}
+
+// ModeFromEnv returns the colors the terminal shows, truecolor when COLORTERM
+// tells so, 256 colors for a TERM like xterm-256color and 16 otherwise
+func ModeFromEnv() Mode {
+	if colorTerm := os.Getenv("COLORTERM"); colorTerm == "truecolor" || colorTerm == "24bit" {
+		return ModeTrueColor
+	}
+
+	if strings.Contains(os.Getenv("TERM"), "256color") {
+		return Mode256
+	}
+
+	return Mode16
+}
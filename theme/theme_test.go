@@ -0,0 +1,128 @@
+package theme
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		text string
+		want Color
+		err  bool
+	}{
+		{"red", Color{Basic: Red}, false},
+		{"Bright-Cyan", Color{Basic: BrightCyan}, false},
+		{"default", Color{}, false},
+		{"#ff0000", Color{Basic: BrightRed, RGB: [3]uint8{255, 0, 0}, Exact: true}, false},
+		{"#0072B2/blue", Color{Basic: Blue, RGB: [3]uint8{0x00, 0x72, 0xb2}, Exact: true}, false},
+		{"purple", Color{}, true},
+		{"#", Color{}, true},
+		{"#12345", Color{}, true},
+		{"#1234567", Color{}, true},
+		{"#gg0000", Color{}, true},
+		{"#+12345", Color{}, true},
+		{"#ff0000/", Color{}, true},
+		{"#ff0000/purple", Color{}, true},
+		{"#ff0000/default", Color{}, true},
+		{"#ff0000/#00ff00", Color{}, true},
+	}
+
+	for _, test := range tests {
+		c, err := ParseColor(test.text)
+		if (err != nil) != test.err || c != test.want {
+			t.Errorf("ParseColor(%q) = %+v, %v, want %+v, error %v", test.text, c, err, test.want, test.err)
+		}
+	}
+}
+
+func TestParseStyle(t *testing.T) {
+	blue := Color{Basic: Blue, RGB: [3]uint8{0x00, 0x72, 0xb2}, Exact: true}
+
+	tests := []struct {
+		text string
+		want Style
+		err  bool
+	}{
+		{"", Style{}, false},
+		{"bold", Style{Bold: true}, false},
+		{"white", Style{Fg: Color{Basic: White}}, false},
+		{"on red", Style{Bg: Color{Basic: Red}}, false},
+		{"bold underline reverse white on blue", Style{Fg: Color{Basic: White}, Bg: Color{Basic: Blue}, Bold: true, Underline: true, Reverse: true}, false},
+		{"Bold  Black on #0072b2/blue", Style{Fg: Color{Basic: Black}, Bg: blue, Bold: true}, false},
+		{"on", Style{}, true},
+		{"white on", Style{}, true},
+		{"white blue", Style{}, true},
+		{"white on red green", Style{}, true},
+		{"bold shiny", Style{}, true},
+		{"white bold", Style{}, true},
+		{"on #00gg00", Style{}, true},
+	}
+
+	for _, test := range tests {
+		s, err := ParseStyle(test.text)
+		if (err != nil) != test.err || s != test.want {
+			t.Errorf("ParseStyle(%q) = %+v, %v, want %+v, error %v", test.text, s, err, test.want, test.err)
+		}
+	}
+}
+
+func TestIndex256(t *testing.T) {
+	tests := []struct {
+		color Color
+		want  int
+	}{
+		// basic colors are the first 16
+		{Color{Basic: Black}, 0},
+		{Color{Basic: Red}, 1},
+		{Color{Basic: BrightWhite}, 15},
+
+		// exact colors, the corners of the cube
+		{RGB(0, 0, 0), 16},
+		{RGB(255, 0, 0), 196},
+		{RGB(255, 255, 255), 231},
+
+		// the closest level of each component
+		{RGB(0x00, 0x72, 0xb2), 25},
+
+		// grays closer to the gray ramp than to the cube
+		{RGB(128, 128, 128), 244},
+		{RGB(8, 8, 8), 232},
+		{RGB(238, 238, 238), 255},
+	}
+
+	for _, test := range tests {
+		if index := test.color.Index256(); index != test.want {
+			t.Errorf("%+v: index %v, want %v", test.color, index, test.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	theme, err := Parse(strings.NewReader("# my theme\nbase = monochrome\n\ntile = bold white on #0072b2/blue\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if theme.Tile.Bg.Basic != Blue || !theme.Tile.Bold || theme.Border != Themes["monochrome"].Border {
+		t.Errorf("tile %+v, border %+v, want the tile changed on the monochrome theme", theme.Tile, theme.Border)
+	}
+
+	for _, text := range []string{"base = plaid", "tile", "shadow = black", "tile = white on"} {
+		if _, err := Parse(strings.NewReader(text)); err == nil {
+			t.Errorf("Parse(%q): no error", text)
+		}
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for m := Mode16; m <= ModeTrueColor; m++ {
+		if parsed, err := ParseMode(m.String()); err != nil || parsed != m {
+			t.Errorf("ParseMode(%q) = %v, %v", m.String(), parsed, err)
+		}
+	}
+
+	if _, err := ParseMode("88"); err == nil {
+		t.Error("ParseMode(\"88\"): no error")
+	}
+}
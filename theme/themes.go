@@ -0,0 +1,87 @@
+package theme
+
+// classic is the theme puzzl always had
+var classic = &Theme{
+	Name:    "classic",
+	Border:  Style{Bg: Color{Basic: Cyan}},
+	Tile:    Style{Bg: Color{Basic: Blue}},
+	Blank:   Style{Bg: Color{Basic: Red}},
+	Banner:  Style{Bg: Color{Basic: Yellow}},
+	Value:   Style{Bg: Color{Basic: Magenta}},
+	Frame:   Style{Bg: Color{Basic: Blue}},
+	Accent:  Style{Fg: Color{Basic: Yellow}},
+	Info:    Style{Bg: Color{Basic: Cyan}},
+	Success: Style{Bg: Color{Basic: Green}},
+	Warning: Style{Bg: Color{Basic: Yellow}},
+	Error:   Style{Bg: Color{Basic: Red}},
+}
+
+// monochrome uses attributes only, for terminals without colors
+var monochrome = &Theme{
+	Name:    "monochrome",
+	Tile:    Style{Reverse: true},
+	Banner:  Style{Bold: true},
+	Value:   Style{Underline: true},
+	Accent:  Style{Bold: true},
+	Success: Style{Bold: true},
+	Warning: Style{Underline: true},
+	Error:   Style{Bold: true, Reverse: true},
+}
+
+// highContrast uses bright colors on black, every text in bold
+var highContrast = &Theme{
+	Name:    "high-contrast",
+	Border:  Style{Fg: Color{Basic: BrightWhite}, Bg: Color{Basic: Black}},
+	Tile:    Style{Fg: Color{Basic: Black}, Bg: Color{Basic: BrightWhite}, Bold: true},
+	Blank:   Style{Bg: Color{Basic: Black}},
+	Banner:  Style{Fg: Color{Basic: Black}, Bg: Color{Basic: BrightYellow}, Bold: true},
+	Value:   Style{Fg: Color{Basic: BrightWhite}, Bg: Color{Basic: Black}, Bold: true},
+	Frame:   Style{Fg: Color{Basic: BrightWhite}, Bg: Color{Basic: Black}},
+	Accent:  Style{Fg: Color{Basic: BrightYellow}, Bold: true},
+	Text:    Style{Bold: true},
+	Info:    Style{Fg: Color{Basic: Black}, Bg: Color{Basic: BrightCyan}, Bold: true},
+	Success: Style{Fg: Color{Basic: Black}, Bg: Color{Basic: BrightGreen}, Bold: true},
+	Warning: Style{Fg: Color{Basic: Black}, Bg: Color{Basic: BrightYellow}, Bold: true},
+	Error:   Style{Fg: Color{Basic: BrightWhite}, Bg: Color{Basic: Red}, Bold: true},
+}
+
+// colorblind uses the Okabe-Ito palette, told apart with red-green color blindness
+// Success and error differ in brightness and attributes as well.
+var colorblind = &Theme{
+	Name:    "colorblind",
+	Border:  Style{Bg: rgb(0x56b4e9, Cyan)},
+	Tile:    Style{Fg: Color{Basic: BrightWhite}, Bg: rgb(0x0072b2, Blue), Bold: true},
+	Blank:   Style{Bg: rgb(0xe69f00, Yellow)},
+	Banner:  Style{Fg: Color{Basic: Black}, Bg: rgb(0xf0e442, BrightYellow)},
+	Value:   Style{Fg: Color{Basic: Black}, Bg: rgb(0xcc79a7, Magenta)},
+	Frame:   Style{Bg: rgb(0x0072b2, Blue)},
+	Accent:  Style{Fg: rgb(0xe69f00, Yellow)},
+	Info:    Style{Fg: Color{Basic: Black}, Bg: rgb(0x56b4e9, Cyan)},
+	Success: Style{Fg: Color{Basic: Black}, Bg: rgb(0x009e73, Green)},
+	Warning: Style{Fg: Color{Basic: Black}, Bg: rgb(0xf0e442, BrightYellow), Underline: true},
+	Error:   Style{Fg: Color{Basic: BrightWhite}, Bg: rgb(0xd55e00, Red), Bold: true},
+}
+
+// tritan avoids telling blue from yellow, for blue-yellow color blindness
+var tritan = &Theme{
+	Name:    "tritan",
+	Border:  Style{Bg: rgb(0x808080, BrightBlack)},
+	Tile:    Style{Fg: Color{Basic: BrightWhite}, Bg: rgb(0x005f73, Cyan), Bold: true},
+	Blank:   Style{Bg: rgb(0xe4002b, Red)},
+	Banner:  Style{Fg: Color{Basic: Black}, Bg: rgb(0xf4a3a8, BrightRed)},
+	Value:   Style{Fg: Color{Basic: BrightWhite}, Bg: rgb(0x8b0046, Magenta)},
+	Frame:   Style{Bg: rgb(0x005f73, Cyan)},
+	Accent:  Style{Fg: rgb(0xe4002b, Red)},
+	Info:    Style{Fg: Color{Basic: Black}, Bg: rgb(0xa0e0e0, BrightCyan)},
+	Success: Style{Fg: Color{Basic: BrightWhite}, Bg: rgb(0x007a5e, Green)},
+	Warning: Style{Fg: Color{Basic: Black}, Bg: rgb(0xf4a3a8, BrightRed), Underline: true},
+	Error:   Style{Fg: Color{Basic: BrightWhite}, Bg: rgb(0xe4002b, Red), Bold: true},
+}
+
+// rgb returns an exact color like 0x0072b2, shown as a chosen basic color on other terminals
+func rgb(value uint32, fallback Basic) Color {
+	c := RGB(uint8(value>>16), uint8(value>>8), uint8(value))
+	c.Basic = fallback
+
+	return c
+}
@@ -0,0 +1,159 @@
+// Package verify checks solutions received as move strings
+//
+// Moves are the directions of the blank tile written as U, D, L and R,
+// as printed by the batch solver. They are replayed on a board one by one
+// and the first move that can not be made is reported.
+package verify
+
+import (
+	"fmt"
+	"github.com/pravj/puzzl/board"
+	"github.com/pravj/puzzl/solver"
+	"strings"
+	"unicode"
+)
+
+// Failure describes the point where a solution stops being valid
+type Failure struct {
+	// one-based number of the failing move, one past the last move
+	// when all of them were made without reaching the goal
+	Step int
+
+	// the failing move as written, empty when the goal was not reached
+	Move string
+
+	// board configuration right before the failure
+	Board board.Board
+
+	Reason string
+}
+
+// Error returns the failure as a sentence
+func (f *Failure) Error() string {
+	if f.Move == "" {
+		return fmt.Sprintf("verify: after move %v: %v", f.Step-1, f.Reason)
+	}
+
+	return fmt.Sprintf("verify: move %v (%v): %v", f.Step, f.Move, f.Reason)
+}
+
+// Result holds the outcome of verifying a solution
+type Result struct {
+	Start board.Board
+	Goal  board.Board
+
+	// number of moves replayed and the configuration they lead to
+	Moves int
+	Final board.Board
+
+	// nil when the moves are legal and reach the goal
+	Failure *Failure
+
+	// length of the optimal solution, -1 when the goal can not be reached
+	Optimal int
+}
+
+// Valid returns whether the moves are legal and reach the goal
+func (r Result) Valid() bool {
+	return r.Failure == nil
+}
+
+// IsOptimal returns whether the solution is valid and as short as possible
+func (r Result) IsOptimal() bool {
+	return r.Valid() && r.Moves == r.Optimal
+}
+
+// Replay makes the moves on a copy of the start board and calls visit,
+// when not nil, with the board after each of them
+// Whitespace and commas between the moves are ignored.
+func Replay(start board.Board, moves string, visit func(step int, b board.Board)) (board.Board, *Failure) {
+	b := start
+	step := 0
+
+	for _, r := range moves {
+		if unicode.IsSpace(r) || r == ',' {
+			continue
+		}
+		step++
+
+		d, err := board.ParseDirection(r)
+		if err != nil {
+			return b, &Failure{Step: step, Move: string(r), Board: b, Reason: "unknown move"}
+		}
+
+		if !b.Slide(d) {
+			return b, &Failure{Step: step, Move: string(r), Board: b, Reason: "blank tile would leave the board"}
+		}
+
+		if visit != nil {
+			visit(step, b)
+		}
+	}
+
+	return b, nil
+}
+
+// Verify replays the moves from the start board, checks that they reach
+// the goal and compares their number with the optimal solution length
+func Verify(start, goal board.Board, moves string) Result {
+	result := Result{Start: start, Goal: goal, Optimal: OptimalLength(start, goal)}
+
+	final, failure := Replay(start, moves, func(step int, b board.Board) {
+		result.Moves = step
+	})
+	result.Final = final
+
+	if failure == nil && final != goal {
+		failure = &Failure{Step: result.Moves + 1, Board: final, Reason: "goal configuration not reached"}
+	}
+	result.Failure = failure
+
+	return result
+}
+
+// OptimalLength returns the number of moves of an optimal solution,
+// -1 when the goal can not be reached from the start
+//
+// The solver finds it for the usual goal, other goals are measured
+// with a breadth first search from them.
+func OptimalLength(start, goal board.Board) int {
+	if goal == *board.Goal() {
+		s := solver.NewWithHeuristic(&start, solver.Manhattan{})
+		s.Solve()
+
+		if !s.Solved {
+			return -1
+		}
+
+		return s.Moves
+	}
+
+	distance, ok := solver.NewOptimal(goal).Distance(start)
+	if !ok {
+		return -1
+	}
+
+	return distance
+}
+
+// Summary returns the result as a single line of text
+func (r Result) Summary() string {
+	var text strings.Builder
+
+	if r.Valid() {
+		fmt.Fprintf(&text, "valid solution of %v moves", r.Moves)
+	} else {
+		fmt.Fprintf(&text, "invalid solution, %v", strings.TrimPrefix(r.Failure.Error(), "verify: "))
+	}
+
+	switch {
+	case r.Optimal < 0:
+		text.WriteString(", goal can not be reached")
+	case r.IsOptimal():
+		text.WriteString(", optimal")
+	default:
+		fmt.Fprintf(&text, ", optimal is %v moves", r.Optimal)
+	}
+
+	return text.String()
+}
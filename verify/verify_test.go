@@ -0,0 +1,77 @@
+package verify
+
+import (
+	"github.com/pravj/puzzl/board"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name  string
+		start string
+		goal  string
+		moves string
+
+		valid   bool
+		optimal int
+		made    int
+
+		// the failure, a zero step when valid
+		step int
+		move string
+	}{
+		{"optimal", "1 2 3 4 5 6 0 7 8", "", "RR", true, 2, 2, 0, ""},
+		{"separators", "1 2 3 4 5 6 0 7 8", "", "r, R", true, 2, 2, 0, ""},
+		{"valid but not optimal", "1 2 3 4 5 6 0 7 8", "", "RLRR", true, 2, 4, 0, ""},
+		{"first illegal move", "1 2 3 4 5 6 0 7 8", "", "RLDRR", false, 2, 2, 3, "D"},
+		{"unknown move", "1 2 3 4 5 6 0 7 8", "", "RX", false, 2, 1, 2, "X"},
+		{"goal not reached", "1 2 3 4 5 6 0 7 8", "", "R", false, 2, 1, 2, ""},
+		{"no moves", "1 2 3 4 5 6 0 7 8", "", "", false, 2, 0, 1, ""},
+		{"custom goal", "1 2 3 4 5 6 0 7 8", "1 2 3 4 5 6 7 0 8", "R", true, 1, 1, 0, ""},
+		{"custom goal missed", "1 2 3 4 5 6 0 7 8", "1 2 3 4 5 6 7 0 8", "RR", false, 1, 2, 3, ""},
+	}
+
+	for _, test := range tests {
+		start, err := board.Parse(test.start)
+		if err != nil {
+			t.Fatal(err)
+		}
+		goal := board.Goal()
+		if test.goal != "" {
+			if goal, err = board.Parse(test.goal); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		r := Verify(*start, *goal, test.moves)
+		if r.Valid() != test.valid || r.Optimal != test.optimal || r.Moves != test.made {
+			t.Errorf("%v: valid %v, %v moves, optimal %v, want %v, %v and %v", test.name, r.Valid(), r.Moves, r.Optimal, test.valid, test.made, test.optimal)
+		}
+		if r.IsOptimal() != (test.valid && test.made == test.optimal) {
+			t.Errorf("%v: optimal %v", test.name, r.IsOptimal())
+		}
+
+		if test.valid {
+			if r.Final != *goal {
+				t.Errorf("%v: ended on %v, want the goal", test.name, r.Final)
+			}
+			continue
+		}
+		if r.Failure == nil || r.Failure.Step != test.step || r.Failure.Move != test.move {
+			t.Errorf("%v: failure %v, want move %v (%q)", test.name, r.Failure, test.step, test.move)
+		}
+	}
+}
+
+func TestReplayVisits(t *testing.T) {
+	start, _ := board.Parse("1 2 3 4 5 6 0 7 8")
+
+	var steps []int
+	final, failure := Replay(*start, "R R", func(step int, b board.Board) {
+		steps = append(steps, step)
+	})
+
+	if failure != nil || final != *board.Goal() || len(steps) != 2 || steps[0] != 1 || steps[1] != 2 {
+		t.Errorf("Replay: visited %v, ended on %v with failure %v", steps, final, failure)
+	}
+}